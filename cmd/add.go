@@ -70,8 +70,9 @@ func addTag(values []string) {
 	db, err := getDatabase()
 	fatalOnError(err)
 
+	op := newOperation()
 	for _, value := range values {
-		tag, created, err := model.FindOrCreateTagByName(db, value)
+		tag, created, err := op.FindOrCreateTagByName(db, value)
 		if err != nil {
 			output.Error(err.Error())
 		} else {
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+var removeAliases bool
+
+// AliasCmd manages a tag's aliases
+var AliasCmd = &cobra.Command{
+	Use:   "alias <tag> [alias]...",
+	Short: "Manage a tag's aliases",
+	Long: `Add the names specified by [alias] as aliases for the tag specified by <tag>, so tagging a star
+with an alias tags it with <tag> instead. Use [--remove] to remove the aliases instead, or leave out [alias]
+to list the tag's aliases.`,
+	Example: fmt.Sprintf("  %s tag alias javascript js ecmascript", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		if len(args) == 0 {
+			output.Fatal("You must specify a tag")
+		}
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		tag, err := model.FindTagByName(db, args[0])
+		fatalOnError(err)
+
+		if tag == nil {
+			output.Fatal(fmt.Sprintf("Tag '%s' not found", args[0]))
+		}
+
+		if len(args) == 1 {
+			aliases, err := tag.FindAliases(db)
+			fatalOnError(err)
+
+			for _, alias := range aliases {
+				output.Info(alias.Name)
+			}
+			return
+		}
+
+		for _, name := range args[1:] {
+			if removeAliases {
+				err = tag.RemoveAlias(db, name)
+			} else {
+				err = tag.AddAlias(db, name)
+			}
+
+			if err != nil {
+				output.Error(err.Error())
+			} else if removeAliases {
+				output.Info(fmt.Sprintf("Removed alias '%s' from tag '%s'", name, tag.Name))
+			} else {
+				output.Info(fmt.Sprintf("Added alias '%s' to tag '%s'", name, tag.Name))
+			}
+		}
+	},
+}
+
+func init() {
+	AliasCmd.Flags().BoolVarP(&removeAliases, "remove", "r", false, "Remove the aliases")
+	TagCmd.AddCommand(AliasCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, AliasCmd.Use)
+}
+
+func TestAliasCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, AliasCmd.Short)
+}
+
+func TestAliasCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, AliasCmd.Long)
+}
+
+func TestAliasCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, AliasCmd.Run)
+}
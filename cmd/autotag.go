@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+// AutotagCmd tags stars using rules
+var AutotagCmd = &cobra.Command{
+	Use:   "autotag",
+	Short: "Tag stars using rules",
+	Long: `Tag your stars using the rules in your configuration, such as:
+  language == "Go" && description =~ /(?i)cli/ => cli, go-tools
+Use [--dry-run] to see which tags would be added without adding them.`,
+	Example: fmt.Sprintf("  %s autotag --dry-run", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		cfg, err := getConfiguration()
+		fatalOnError(err)
+
+		if len(cfg.Rules) == 0 {
+			output.Fatal("You have no rules in your configuration")
+		}
+
+		rules, err := model.ParseRules(cfg.Rules)
+		fatalOnError(err)
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		index, err := getIndex()
+		fatalOnError(err)
+
+		stars, err := findStarsByLanguageAndTag(db, "")
+		fatalOnError(err)
+
+		matches, err := model.PlanRules(db, rules, stars)
+		fatalOnError(err)
+
+		op := newOperation()
+		totalTagged, totalErrors := 0, 0
+		for _, match := range matches {
+			output.StarLine(match.Star)
+			output.Info(fmt.Sprintf("Add [%s]", strings.Join(match.Tags, ", ")))
+			if dryRun {
+				continue
+			}
+
+			if err := match.Apply(db, op); err != nil {
+				totalErrors++
+				output.Error(err.Error())
+				continue
+			}
+			if err := match.Star.Index(index, db); err != nil {
+				totalErrors++
+				output.Error(err.Error())
+				continue
+			}
+			totalTagged++
+		}
+
+		if dryRun {
+			output.Info(fmt.Sprintf("Stars to tag: %d", len(matches)))
+		} else {
+			output.Info(fmt.Sprintf("Stars tagged: %d; Errors: %d", totalTagged, totalErrors))
+		}
+	},
+}
+
+func init() {
+	AutotagCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Display what would change without changing anything")
+	RootCmd.AddCommand(AutotagCmd)
+}
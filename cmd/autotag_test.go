@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutotagCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, AutotagCmd.Use)
+}
+
+func TestAutotagCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, AutotagCmd.Short)
+}
+
+func TestAutotagCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, AutotagCmd.Long)
+}
+
+func TestAutotagCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, AutotagCmd.Run)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/tui"
+	"github.com/spf13/cobra"
+)
+
+// BrowseCmd browses and tags stars in a full-screen terminal UI
+var BrowseCmd = &cobra.Command{
+	Use:   "browse",
+	Short: "Browse and tag stars",
+	Long: `Browse, search, and tag your stars in a full-screen terminal UI.
+It locks your database until you quit, so other limo commands fail while it runs.`,
+	Example: fmt.Sprintf("  %s browse", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		index, err := getIndex()
+		fatalOnError(err)
+
+		browser, err := tui.New(db, index)
+		fatalOnError(err)
+
+		fatalOnError(browser.Run(os.Stdin, os.Stdout))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(BrowseCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrowseCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, BrowseCmd.Use)
+}
+
+func TestBrowseCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, BrowseCmd.Short)
+}
+
+func TestBrowseCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, BrowseCmd.Long)
+}
+
+func TestBrowseCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, BrowseCmd.Run)
+}
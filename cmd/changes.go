@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+var since = ""
+
+// changeLabels describe each kind of change in summaries
+var changeLabels = map[string]string{
+	model.ChangeStarred:            "Starred",
+	model.ChangeUnstarred:          "Unstarred",
+	model.ChangeRenamed:            "Renamed",
+	model.ChangeLanguage:           "Changed language",
+	model.ChangeDescriptionRemoved: "Removed description",
+}
+
+// ChangesCmd shows what updates changed
+var ChangesCmd = &cobra.Command{
+	Use:   "changes",
+	Short: "Show what updates changed",
+	Long: `Show which stars your updates found newly starred, unstarred, renamed, moved to a different language,
+or without their descriptions. By default, show the last update. Use [--since] with an update's number to
+show that update and the ones after it, or with a date like 2023-01-01 or a time ago like 30d to show the
+updates since then.`,
+	Example: fmt.Sprintf("  %s changes\n  %s changes --since 30d", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		var runs []model.UpdateRun
+		if since == "" {
+			run, err := model.FindLastUpdateRun(db)
+			fatalOnError(err)
+			if run != nil {
+				runs = append(runs, *run)
+			}
+		} else if ID, err := strconv.ParseUint(since, 10, 64); err == nil {
+			runs, err = model.FindUpdateRunsFrom(db, uint(ID))
+			fatalOnError(err)
+		} else {
+			date, err := time.Parse("2006-01-02", since)
+			if err != nil {
+				date, err = model.ParseTimeAgo(since, time.Now())
+				if err != nil {
+					output.Fatal(fmt.Sprintf("'%s' isn't an update number, a date, or a time ago like 30d", since))
+				}
+			}
+			runs, err = model.FindUpdateRunsSince(db, date)
+			fatalOnError(err)
+		}
+
+		if len(runs) == 0 {
+			output.Info("No updates found")
+			return
+		}
+
+		for _, run := range runs {
+			header := fmt.Sprintf("Update %d", run.ID)
+			if service, err := model.FindServiceByID(db, run.ServiceID); err == nil {
+				header = fmt.Sprintf("%s from %s", header, service.Name)
+			}
+			output.Info(fmt.Sprintf("%s on %s: Created: %d; Updated: %d; Errors: %d",
+				header, run.CreatedAt.Format(time.UnixDate), run.Created, run.Updated, run.Errors))
+
+			for _, kind := range model.ChangeKinds {
+				for _, change := range run.Changes {
+					if change.Kind == kind {
+						output.Info(change.String())
+					}
+				}
+			}
+		}
+	},
+}
+
+// changeSummary sums up the notable changes an update found
+func changeSummary(run *model.UpdateRun) string {
+	if run == nil {
+		return ""
+	}
+
+	counts := run.CountChanges()
+	var parts []string
+	for _, kind := range model.ChangeKinds {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", changeLabels[kind], counts[kind]))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func init() {
+	ChangesCmd.Flags().StringVar(&since, "since", "", "Update number, date, or time ago to show changes since")
+	RootCmd.AddCommand(ChangesCmd)
+}
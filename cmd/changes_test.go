@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangesCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, ChangesCmd.Use)
+}
+
+func TestChangesCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, ChangesCmd.Short)
+}
+
+func TestChangesCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, ChangesCmd.Long)
+}
+
+func TestChangesCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, ChangesCmd.Run)
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+var jobs = 4
+var updateClones = false
+
+type cloneResult struct {
+	star      *model.Star
+	localPath string
+	action    string
+	err       error
+}
+
+// CloneCmd clones starred repositories into your workspace
+var CloneCmd = &cobra.Command{
+	Use:   "clone [star]",
+	Short: "Clone stars locally",
+	Long: `Clone the star identified by [star], or the stars matching [--tag] and [--language], into your workspace.
+Checkouts are laid out as host/owner/repo. Existing checkouts are skipped, or fetched with [--update].`,
+	Example: fmt.Sprintf("  %s clone limo\n  %s clone --tag vim --update", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		output := getOutput()
+
+		cfg, err := getConfiguration()
+		fatalOnError(err)
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		var stars []model.Star
+		if len(args) > 0 {
+			stars, err = model.FuzzyFindStarsByName(db, args[0])
+			fatalOnError(err)
+			checkOneStar(args[0], stars)
+		} else {
+			stars, err = findStarsByLanguageAndTag(db, "")
+			fatalOnError(err)
+		}
+
+		if jobs < 1 {
+			jobs = 1
+		}
+
+		starChan := make(chan *model.Star)
+		resultChan := make(chan *cloneResult)
+
+		var wg sync.WaitGroup
+		for i := 0; i < jobs; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for star := range starChan {
+					resultChan <- cloneStar(ctx, cfg.WorkspacePath, star)
+				}
+			}()
+		}
+
+		go func() {
+			for i := range stars {
+				starChan <- &stars[i]
+			}
+			close(starChan)
+			wg.Wait()
+			close(resultChan)
+		}()
+
+		// Only this goroutine touches the database
+		totals := make(map[string]int)
+		for result := range resultChan {
+			output.StarLine(result.star)
+			if result.err != nil {
+				totals["errors"]++
+				output.Error(result.err.Error())
+				continue
+			}
+			totals[result.action]++
+			output.Info(fmt.Sprintf("%s %s", result.action, result.localPath))
+			if result.star.LocalPath == nil || *result.star.LocalPath != result.localPath {
+				if err := result.star.SetLocalPath(db, result.localPath); err != nil {
+					output.Error(err.Error())
+				}
+			}
+		}
+
+		output.Info(fmt.Sprintf("Cloned: %d; Fetched: %d; Skipped: %d; Errors: %d",
+			totals["Cloned"], totals["Fetched"], totals["Skipped"], totals["errors"]))
+	},
+}
+
+func cloneStar(ctx context.Context, root string, star *model.Star) *cloneResult {
+	result := &cloneResult{
+		star: star,
+	}
+
+	result.localPath, result.err = star.WorkspacePath(root)
+	if result.err != nil {
+		return result
+	}
+
+	if _, err := os.Stat(filepath.Join(result.localPath, ".git")); err == nil {
+		if !updateClones {
+			result.action = "Skipped"
+			return result
+		}
+		result.action = "Fetched"
+		result.err = runGit(ctx, "-C", result.localPath, "fetch", "--all", "--prune")
+		return result
+	}
+
+	if err := os.MkdirAll(filepath.Dir(result.localPath), 0700); err != nil {
+		result.err = err
+		return result
+	}
+	result.action = "Cloned"
+	result.err = runGit(ctx, "clone", "--quiet", "--", *star.URL, result.localPath)
+	return result
+}
+
+func runGit(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("git: %s", msg)
+		}
+		return err
+	}
+	return nil
+}
+
+func init() {
+	CloneCmd.Flags().IntVarP(&jobs, "jobs", "j", 4, "Number of repositories to clone at once")
+	CloneCmd.Flags().BoolVarP(&updateClones, "update", "u", false, "Fetch existing checkouts")
+	RootCmd.AddCommand(CloneCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, CloneCmd.Use)
+}
+
+func TestCloneCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, CloneCmd.Short)
+}
+
+func TestCloneCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, CloneCmd.Long)
+}
+
+func TestCloneCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, CloneCmd.Run)
+}
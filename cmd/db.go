@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/jinzhu/gorm"
+	"github.com/spf13/cobra"
+)
+
+// DBCmd manages your local database
+var DBCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage your local database",
+	Long: `Manage your local database's schema. Limo applies pending migrations automatically, backing up
+your database first, so you only need these commands to check on or retry migrations.`,
+	Example: fmt.Sprintf("  %s db status\n  %s db migrate", config.ProgramName, config.ProgramName),
+}
+
+// DBMigrateCmd applies pending migrations
+var DBMigrateCmd = &cobra.Command{
+	Use:     "migrate",
+	Short:   "Apply pending migrations",
+	Long:    "Apply the migrations your local database doesn't have yet, backing it up first.",
+	Example: fmt.Sprintf("  %s db migrate", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		cfg, err := getConfiguration()
+		fatalOnError(err)
+
+		db, err := openDatabase(cfg)
+		fatalOnError(err)
+
+		applied, backup, err := model.Migrate(db, cfg.GetDatabaseURL())
+		if backup != "" {
+			output.Info(fmt.Sprintf("Backed up database to %s", backup))
+		}
+		for _, migration := range applied {
+			output.Info(fmt.Sprintf("Applied %d: %s", migration.Version, migration.Name))
+		}
+		fatalOnError(err)
+
+		if len(applied) == 0 {
+			output.Info("Database is up to date")
+		}
+	},
+}
+
+// DBStatusCmd shows which migrations have been applied
+var DBStatusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Show which migrations have been applied",
+	Long:    "Show each migration and when it was applied to your local database, or that it's pending.",
+	Example: fmt.Sprintf("  %s db status", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		cfg, err := getConfiguration()
+		fatalOnError(err)
+
+		db, err := openDatabase(cfg)
+		fatalOnError(err)
+
+		statuses, err := model.FindMigrationStatus(db)
+		fatalOnError(err)
+
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = fmt.Sprintf("applied %s", status.AppliedAt.Format(time.UnixDate))
+			}
+			output.Info(fmt.Sprintf("%d: %s (%s)", status.Version, status.Name, applied))
+		}
+	},
+}
+
+// openDatabase opens the database without applying migrations, so the db commands can apply them
+func openDatabase(cfg *config.Config) (*gorm.DB, error) {
+	if err := lockStore(cfg); err != nil {
+		return nil, err
+	}
+	return model.OpenDB(cfg.GetDatabaseURL(), options.verbose)
+}
+
+func init() {
+	DBCmd.AddCommand(DBMigrateCmd)
+	DBCmd.AddCommand(DBStatusCmd)
+	RootCmd.AddCommand(DBCmd)
+}
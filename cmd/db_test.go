@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, DBCmd.Use)
+}
+
+func TestDBCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, DBCmd.Short)
+}
+
+func TestDBCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, DBCmd.Long)
+}
+func TestDBMigrateCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, DBMigrateCmd.Use)
+}
+
+func TestDBMigrateCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, DBMigrateCmd.Short)
+}
+
+func TestDBMigrateCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, DBMigrateCmd.Long)
+}
+
+func TestDBMigrateCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, DBMigrateCmd.Run)
+}
+func TestDBStatusCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, DBStatusCmd.Use)
+}
+
+func TestDBStatusCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, DBStatusCmd.Short)
+}
+
+func TestDBStatusCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, DBStatusCmd.Long)
+}
+
+func TestDBStatusCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, DBStatusCmd.Run)
+}
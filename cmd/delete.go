@@ -62,7 +62,7 @@ func deleteStar(values []string) {
 	dbStar, err := model.FindStarByRemoteIDAndService(db, star.RemoteID, dbSvc)
 	fatalOnError(err)
 
-	err = dbStar.Delete(db)
+	err = newOperation().DeleteStar(db, dbStar)
 	fatalOnError(err)
 
 	getOutput().Info("Deleted star")
@@ -74,6 +74,7 @@ func deleteTag(values []string) {
 	db, err := getDatabase()
 	fatalOnError(err)
 
+	op := newOperation()
 	for _, value := range values {
 		tag, err := model.FindTagByName(db, value)
 		if err != nil {
@@ -82,7 +83,7 @@ func deleteTag(values []string) {
 			if tag == nil {
 				output.Error(fmt.Sprintf("Tag '%s' not found", value))
 			} else {
-				err = tag.Delete(db)
+				err = op.DeleteTag(db, tag)
 				if err != nil {
 					output.Error(err.Error())
 				} else {
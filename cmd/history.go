@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+// HistoryCmd shows the commands that changed your local database
+var HistoryCmd = &cobra.Command{
+	Use:   "history [operation]",
+	Short: "Show the changes you've made",
+	Long: `Show the commands that changed your local database's tags -- including changes made through browse,
+serve, and web -- most recent first. Specify [operation] by its number to show each change it made.`,
+	Example: fmt.Sprintf("  %s history\n  %s history 12", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		if len(args) > 0 {
+			ID, parseErr := strconv.ParseUint(args[0], 10, 64)
+			if parseErr != nil {
+				output.Fatal(fmt.Sprintf("'%s' isn't an operation number", args[0]))
+			}
+
+			op, err := model.FindOperationByID(db, uint(ID))
+			fatalOnError(err)
+
+			output.Info(operationLine(op))
+			for _, step := range op.Steps {
+				output.Info(fmt.Sprintf("  %s", step.String()))
+			}
+			return
+		}
+
+		ops, err := model.FindOperations(db)
+		fatalOnError(err)
+
+		if len(ops) == 0 {
+			output.Info("No history")
+			return
+		}
+		for _, op := range ops {
+			output.Info(operationLine(&op))
+		}
+	},
+}
+
+// newOperation starts recording an operation for the command that's running
+func newOperation() *model.Operation {
+	return model.NewOperation(strings.Join(append([]string{config.ProgramName}, os.Args[1:]...), " "))
+}
+
+// operationLine describes an operation on one line
+func operationLine(op *model.Operation) string {
+	line := fmt.Sprintf("%d %s: %s (changes: %d)", op.ID, op.CreatedAt.Format(time.UnixDate), op.Command, len(op.Steps))
+	if op.UndoneAt != nil {
+		line += " (undone)"
+	}
+	return line
+}
+
+func init() {
+	RootCmd.AddCommand(HistoryCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, HistoryCmd.Use)
+}
+
+func TestHistoryCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, HistoryCmd.Short)
+}
+
+func TestHistoryCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, HistoryCmd.Long)
+}
+
+func TestHistoryCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, HistoryCmd.Run)
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/hoop33/entrevista"
@@ -10,14 +12,38 @@ import (
 	"github.com/hoop33/limo/model"
 	"github.com/hoop33/limo/service"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var any = false
+var archived = ""
 var browse = false
 var notTagged = false
 var page = 1
 var count = 1
+var minRating = 0
+var topic = ""
+var trendingBy = ""
+var trendingMinStars = 0
+var trendingPages = 0
+var trendingSince = ""
+var trendingSort = ""
 var user = ""
+var where = ""
+
+// listFlags are ListCmd's flags, for the listers to check which were set without referring to ListCmd
+var listFlags *pflag.FlagSet
+
+var trendingBys = map[string]bool{
+	"created": true,
+	"pushed":  true,
+}
+
+var trendingSorts = map[string]bool{
+	"forks":   true,
+	"stars":   true,
+	"updated": true,
+}
 
 var listers = map[string]func(ctx context.Context, args []string){
 	"events":    listEvents,
@@ -33,7 +59,7 @@ var ListCmd = &cobra.Command{
 	Aliases: []string{"ls"},
 	Short:   "List events, languages, stars, tags, or trending",
 	Long:    "List events, languages, stars, tags, or trending that match your specified criteria.",
-	Example: fmt.Sprintf("  %s list events\n  %s list languages\n  %s list stars -t vim\n  %s list stars -t cli -l go", config.ProgramName, config.ProgramName, config.ProgramName, config.ProgramName),
+	Example: fmt.Sprintf("  %s list events\n  %s list languages\n  %s list stars -t vim\n  %s list stars -t cli -l go\n  %s list stars --topic cli --archived=false\n  %s list stars --where 'tag:cli and (language:go or language:rust) and stars>1000'\n  %s list trending -l go --since month --by pushed --min-stars 100", config.ProgramName, config.ProgramName, config.ProgramName, config.ProgramName, config.ProgramName, config.ProgramName, config.ProgramName),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := context.Background()
 
@@ -117,31 +143,39 @@ func listStars(_ context.Context, args []string) {
 	}
 
 	var stars []model.Star
-	if notTagged {
-		stars, err = model.FindUntaggedStars(db, match)
-	} else if options.language != "" && options.tag != "" {
-		stars, err = model.FindStarsByLanguageAndOrTag(db, match, options.language, options.tag, any)
-	} else if options.language != "" {
-		stars, err = model.FindStarsByLanguage(db, match, options.language)
-	} else if options.tag != "" {
-		tag, err := model.FindTagByName(db, options.tag)
-		fatalOnError(err)
-
-		if tag == nil {
-			output.Fatal(fmt.Sprintf("Tag '%s' not found", options.tag))
+	if where != "" {
+		if notTagged || options.tag != "" || options.language != "" {
+			output.Fatal("You can't combine --where with --notTagged, --tag, or --language")
 		}
 
-		err = tag.LoadStars(db, match)
-		fatalOnError(err)
+		query, parseErr := model.ParseQuery(where)
+		fatalOnError(parseErr)
 
-		stars = tag.Stars
+		stars, err = model.FindStarsByQuery(db, query, match)
+	} else if notTagged {
+		stars, err = model.FindUntaggedStars(db, match)
 	} else {
-		stars, err = model.FindStars(db, match)
+		stars, err = findStarsByLanguageAndTag(db, match)
 	}
 
 	fatalOnError(err)
 
+	filterArchived, showArchived := archived != "", false
+	if filterArchived {
+		showArchived, err = strconv.ParseBool(archived)
+		fatalOnError(err)
+	}
+
 	for _, star := range stars {
+		if filterArchived && star.Archived != showArchived {
+			continue
+		}
+		if topic != "" && !star.HasTopic(topic) {
+			continue
+		}
+		if star.Rating < minRating {
+			continue
+		}
 		output.StarLine(&star)
 		if browse {
 			err := star.OpenInBrowser(false)
@@ -168,9 +202,24 @@ func listTags(_ context.Context, _ []string) {
 			output.Tag(&tag)
 		}
 	}
+
+	// Saved searches act as tags, so list them too
+	searches, err := model.FindSavedSearchesWithStarCount(db)
+	if err != nil {
+		output.Error(err.Error())
+	} else {
+		for _, search := range searches {
+			output.Tag(&model.Tag{
+				Name:      model.SavedSearchPrefix + search.Name,
+				StarCount: search.StarCount,
+			})
+		}
+	}
 }
 
 func listTrending(ctx context.Context, _ []string) {
+	output := getOutput()
+
 	// Get configuration
 	cfg, err := getConfiguration()
 	fatalOnError(err)
@@ -179,19 +228,67 @@ func listTrending(ctx context.Context, _ []string) {
 	svc, err := getService("")
 	fatalOnError(err)
 
+	// Flags override the defaults in the configuration
+	opts := service.TrendingOptions{
+		Language: options.language,
+		By:       cfg.Trending.By,
+		Sort:     cfg.Trending.Sort,
+		Pages:    cfg.Trending.Pages,
+		MinStars: cfg.Trending.MinStars,
+		Verbose:  options.verbose,
+	}
+	sinceText := cfg.Trending.Since
+	if trendingSince != "" {
+		sinceText = trendingSince
+	}
+	if trendingBy != "" {
+		opts.By = trendingBy
+	}
+	if trendingSort != "" {
+		opts.Sort = trendingSort
+	}
+	if listFlags.Changed("pages") {
+		opts.Pages = trendingPages
+	}
+	if listFlags.Changed("min-stars") {
+		opts.MinStars = trendingMinStars
+	}
+
+	opts.Since, err = parseSince(sinceText, time.Now())
+	fatalOnError(err)
+	if !trendingBys[opts.By] {
+		output.Fatal(fmt.Sprintf("'%s' isn't created or pushed", opts.By))
+	}
+	if !trendingSorts[opts.Sort] {
+		output.Fatal(fmt.Sprintf("'%s' isn't stars, forks, or updated", opts.Sort))
+	}
+	if opts.Pages < 1 {
+		output.Fatal("You must list at least one page")
+	}
+
+	// Find what you've already starred, so we can mark it
+	db, err := getDatabase()
+	fatalOnError(err)
+
+	remoteIDs, err := model.FindRemoteIDsByServiceName(db, service.Name(svc))
+	fatalOnError(err)
+
+	starred := make(map[string]bool, len(remoteIDs))
+	for _, remoteID := range remoteIDs {
+		starred[remoteID] = true
+	}
+
 	// Create a channel to receive trending, since service can page
 	trendingChan := make(chan *model.StarResult, 20)
 
 	// Get trending for the specified service
-	go svc.GetTrending(ctx, trendingChan, cfg.GetService(service.Name(svc)).Token, options.language, options.verbose)
-
-	output := getOutput()
+	go svc.GetTrending(ctx, trendingChan, cfg.GetService(service.Name(svc)).Token, opts)
 
 	for starResult := range trendingChan {
 		if starResult.Error != nil {
 			output.Error(starResult.Error.Error())
 		} else {
-			output.StarLine(starResult.Star)
+			output.TrendingLine(starResult.Star, starred[starResult.Star.RemoteID])
 			if browse {
 				err := starResult.Star.OpenInBrowser(false)
 				if err != nil {
@@ -202,6 +299,27 @@ func listTrending(ctx context.Context, _ []string) {
 	}
 }
 
+// parseSince parses how far back to look for trending -- day, week, month, a duration like 72h,
+// or a time ago like 30d -- into the time that long before now
+func parseSince(text string, now time.Time) (time.Time, error) {
+	switch text {
+	case "day":
+		return now.AddDate(0, 0, -1), nil
+	case "week":
+		return now.AddDate(0, 0, -7), nil
+	case "month":
+		return now.AddDate(0, -1, 0), nil
+	}
+
+	if duration, parseErr := time.ParseDuration(text); parseErr == nil && duration > 0 {
+		return now.Add(-duration), nil
+	}
+	if since, parseErr := model.ParseTimeAgo(text, now); parseErr == nil {
+		return since, nil
+	}
+	return now, fmt.Errorf("'%s' isn't day, week, month, or a time ago like 72h or 30d", text)
+}
+
 func getUser() (string, error) {
 	interview := entrevista.NewInterview()
 	interview.ShowOutput = func(message string) {
@@ -228,10 +346,21 @@ func getUser() (string, error) {
 
 func init() {
 	ListCmd.Flags().BoolVarP(&any, "any", "a", false, "Show stars matching any arguments")
+	ListCmd.Flags().StringVar(&archived, "archived", "", "Show only archived stars (--archived=false for only active)")
+	ListCmd.Flags().Lookup("archived").NoOptDefVal = "true"
 	ListCmd.Flags().BoolVarP(&browse, "browse", "b", false, "Open listed items in your default browser")
+	ListCmd.Flags().IntVar(&minRating, "min-rating", 0, "Show only stars rated at least this high")
 	ListCmd.Flags().BoolVarP(&notTagged, "notTagged", "n", false, "Show stars without any tags")
 	ListCmd.Flags().IntVarP(&page, "page", "p", 1, "First event page to list")
 	ListCmd.Flags().IntVarP(&count, "count", "c", 1, "Count of event pages to list")
+	ListCmd.Flags().StringVar(&trendingSince, "since", "", "How far back to look for trending: day, week, month, or a time ago like 72h or 30d (default: trending.since in limo.yaml, or week)")
+	ListCmd.Flags().StringVar(&trendingBy, "by", "", "Find trending created or pushed since then (default: trending.by in limo.yaml, or created)")
+	ListCmd.Flags().StringVar(&trendingSort, "sort", "", "Sort trending by stars, forks, or updated (default: trending.sort in limo.yaml, or stars)")
+	ListCmd.Flags().IntVar(&trendingPages, "pages", 0, "Count of trending pages to list (default: trending.pages in limo.yaml, or 1)")
+	ListCmd.Flags().IntVar(&trendingMinStars, "min-stars", 0, "Show only trending with at least this many stars (default: trending.minStars in limo.yaml)")
+	ListCmd.Flags().StringVar(&topic, "topic", "", "Show stars labeled with a topic")
 	ListCmd.Flags().StringVarP(&user, "user", "u", "", "User for event list")
+	ListCmd.Flags().StringVarP(&where, "where", "w", "", "Show stars matching an expression, like 'tag:cli and not language:go'")
+	listFlags = ListCmd.Flags()
 	RootCmd.AddCommand(ListCmd)
 }
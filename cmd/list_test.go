@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -25,3 +26,21 @@ func TestListCmdHasRun(t *testing.T) {
 func TestListCmdHasAliasLs(t *testing.T) {
 	assert.Equal(t, "ls", ListCmd.Aliases[0])
 }
+
+func TestParseSinceShouldParseNamesDurationsAndTimesAgo(t *testing.T) {
+	now := time.Date(2017, 6, 15, 12, 0, 0, 0, time.UTC)
+	for text, expected := range map[string]time.Time{
+		"day":   time.Date(2017, 6, 14, 12, 0, 0, 0, time.UTC),
+		"week":  time.Date(2017, 6, 8, 12, 0, 0, 0, time.UTC),
+		"month": time.Date(2017, 5, 15, 12, 0, 0, 0, time.UTC),
+		"72h":   time.Date(2017, 6, 12, 12, 0, 0, 0, time.UTC),
+		"2w":    time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC),
+	} {
+		since, err := parseSince(text, now)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, since, text)
+	}
+
+	_, err := parseSince("fortnight", now)
+	assert.NotNil(t, err)
+}
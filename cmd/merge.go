@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+// MergeCmd merges tags into another tag
+var MergeCmd = &cobra.Command{
+	Use:   "merge <into> <from>...",
+	Short: "Merge tags into another tag",
+	Long: `Merge the tags specified by <from> into the tag specified by <into>. Their stars are tagged
+with <into> instead, and they're deleted, with their names kept as aliases for <into>.
+To tag a star named merge instead, run 'tag -- merge <tag>...'.`,
+	Example: fmt.Sprintf("  %s tag merge javascript js JavaScript-libs", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		if len(args) < 2 {
+			output.Fatal("You must specify a tag to merge into and at least one tag to merge")
+		}
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		index, err := getIndex()
+		fatalOnError(err)
+
+		var tags []*model.Tag
+		for _, name := range args {
+			tag, err := model.FindTagByName(db, name)
+			fatalOnError(err)
+
+			if tag == nil {
+				output.Fatal(fmt.Sprintf("Tag '%s' not found", name))
+			}
+			tags = append(tags, tag)
+		}
+
+		starIDs, err := newOperation().MergeTags(db, tags[0], tags[1:])
+		fatalOnError(err)
+
+		totalErrors := 0
+		for _, ID := range starIDs {
+			star, err := model.FindStarByID(db, ID)
+			if err == nil {
+				err = star.Index(index, db)
+			}
+			if err != nil {
+				totalErrors++
+				output.Error(err.Error())
+			}
+		}
+
+		output.Info(fmt.Sprintf("Merged %d tags into '%s'; Stars updated: %d; Errors: %d",
+			len(tags)-1, tags[0].Name, len(starIDs)-totalErrors, totalErrors))
+	},
+}
+
+func init() {
+	TagCmd.AddCommand(MergeCmd)
+}
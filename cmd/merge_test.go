@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, MergeCmd.Use)
+}
+
+func TestMergeCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, MergeCmd.Short)
+}
+
+func TestMergeCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, MergeCmd.Long)
+}
+
+func TestMergeCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, MergeCmd.Run)
+}
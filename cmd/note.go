@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+var edit = false
+
+// NoteCmd sets or displays a star's note
+var NoteCmd = &cobra.Command{
+	Use:   "note <star> [text]",
+	Short: "Set or display a star's note",
+	Long: `Set the note for the star identified by <star> to [text], which can be markdown. Use [--edit]
+to write the note in your $EDITOR instead, or leave out [text] to display the note. Set the note
+to "" to clear it.`,
+	Example: fmt.Sprintf("  %s note limo \"Manages my stars\"\n  %s note limo --edit", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		if len(args) == 0 {
+			output.Fatal("You must specify a star")
+		}
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		stars, err := model.FuzzyFindStarsByName(db, args[0])
+		fatalOnError(err)
+
+		checkOneStar(args[0], stars)
+
+		star := &stars[0]
+		if len(args) == 1 && !edit {
+			if star.Note != nil {
+				output.Info(*star.Note)
+			}
+			return
+		}
+
+		var note string
+		if edit {
+			current := ""
+			if star.Note != nil {
+				current = *star.Note
+			}
+			note, err = editText(current)
+			fatalOnError(err)
+		} else {
+			note = strings.Join(args[1:], " ")
+		}
+
+		fatalOnError(star.SetNote(db, note))
+
+		index, err := getIndex()
+		fatalOnError(err)
+		fatalOnError(star.Index(index, db))
+
+		output.StarLine(star)
+		if star.Note == nil {
+			output.Info("Cleared note")
+		} else {
+			output.Info("Saved note")
+		}
+	},
+}
+
+// editText opens text in your $EDITOR and returns what you saved
+func editText(text string) (string, error) {
+	file, err := ioutil.TempFile("", fmt.Sprintf("%s-*.md", config.ProgramName))
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(text); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	command := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return "", err
+	}
+
+	edited, err := ioutil.ReadFile(file.Name())
+	return string(edited), err
+}
+
+func init() {
+	NoteCmd.Flags().BoolVarP(&edit, "edit", "e", false, "Write the note in your $EDITOR")
+	RootCmd.AddCommand(NoteCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoteCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, NoteCmd.Use)
+}
+
+func TestNoteCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, NoteCmd.Short)
+}
+
+func TestNoteCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, NoteCmd.Long)
+}
+
+func TestNoteCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, NoteCmd.Run)
+}
@@ -33,10 +33,11 @@ var PruneCmd = &cobra.Command{
 		prunable, err := model.FindPrunableStars(db, dbSvc)
 		fatalOnError(err)
 
+		op := newOperation()
 		for _, star := range prunable {
 			output.StarLine(&star)
 			if del {
-				fatalOnError(star.Delete(db))
+				fatalOnError(op.DeleteStar(db, &star))
 			}
 		}
 	},
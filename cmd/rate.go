@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+// RateCmd rates a star
+var RateCmd = &cobra.Command{
+	Use:     "rate <star> <rating>",
+	Short:   "Rate a star",
+	Long:    fmt.Sprintf("Rate the star identified by <star> from 1 to %d, or clear its rating with 0.", model.MaxRating),
+	Example: fmt.Sprintf("  %s rate limo 5", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		if len(args) < 2 {
+			output.Fatal("You must specify a star and a rating")
+		}
+
+		rating, err := strconv.Atoi(args[1])
+		if err != nil {
+			output.Fatal(fmt.Sprintf("'%s' is not a rating", args[1]))
+		}
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		stars, err := model.FuzzyFindStarsByName(db, args[0])
+		fatalOnError(err)
+
+		checkOneStar(args[0], stars)
+
+		fatalOnError(stars[0].SetRating(db, rating))
+
+		output.StarLine(&stars[0])
+		if rating == 0 {
+			output.Info("Cleared rating")
+		} else {
+			output.Info(fmt.Sprintf("Rated %d/%d", rating, model.MaxRating))
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(RateCmd)
+}
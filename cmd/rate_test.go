@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, RateCmd.Use)
+}
+
+func TestRateCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, RateCmd.Short)
+}
+
+func TestRateCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, RateCmd.Long)
+}
+
+func TestRateCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, RateCmd.Run)
+}
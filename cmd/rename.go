@@ -32,7 +32,7 @@ var RenameCmd = &cobra.Command{
 			output.Fatal(fmt.Sprintf("Tag '%s' not found", args[0]))
 		}
 
-		fatalOnError(tag.Rename(db, args[1]))
+		fatalOnError(newOperation().RenameTag(db, tag, args[1]))
 
 		output.Info(fmt.Sprintf("Renamed tag '%s' to '%s'", args[0], tag.Name))
 	},
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/blevesearch/bleve"
 	"github.com/hoop33/limo/config"
@@ -17,6 +18,35 @@ import (
 var configuration *config.Config
 var db *gorm.DB
 var index bleve.Index
+var lock *model.Lock
+
+// readOnlyCommands only read your stars, so they can share the database and search index with other limo processes
+var readOnlyCommands = map[*cobra.Command]bool{
+	ChangesCmd:   true,
+	CountCmd:     true,
+	DBStatusCmd:  true,
+	HistoryCmd:   true,
+	ListCmd:      true,
+	OpenCmd:      true,
+	SearchCmd:    true,
+	ShowCmd:      true,
+	StatsCmd:     true,
+	TrashListCmd: true,
+	TrendsCmd:    true,
+}
+
+// readOnly is whether the running command only reads
+var readOnly = false
+
+// untilStoppedCommands run until you stop them, holding the lock the whole time
+var untilStoppedCommands = map[*cobra.Command]bool{
+	BrowseCmd: true,
+	ServeCmd:  true,
+	WebCmd:    true,
+}
+
+// untilStopped is whether the running command runs until you stop it
+var untilStopped = false
 
 var options struct {
 	insecure bool
@@ -25,6 +55,7 @@ var options struct {
 	service  string
 	tag      string
 	verbose  bool
+	wait     time.Duration
 }
 
 // RootCmd is the root command for limo
@@ -34,6 +65,10 @@ var RootCmd = &cobra.Command{
 	Long: `limo allows you to manage your starred repositories on GitHub, GitLab, and Bitbucket.
 You can tag, display, and search your starred repositories.`,
 	Version: config.Version,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		readOnly = readOnlyCommands[cmd]
+		untilStopped = untilStoppedCommands[cmd]
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -52,6 +87,7 @@ func init() {
 	flags.StringVarP(&options.service, "service", "s", "github", "service")
 	flags.StringVarP(&options.tag, "tag", "t", "", "tag")
 	flags.BoolVarP(&options.verbose, "verbose", "v", false, "verbose output")
+	flags.DurationVar(&options.wait, "wait", 0, "how long to wait for another limo using the database, like 30s")
 }
 
 func getConfiguration() (*config.Config, error) {
@@ -64,34 +100,111 @@ func getConfiguration() (*config.Config, error) {
 	return configuration, nil
 }
 
+// lockStore locks the database and search index for the running command -- shared if it only reads,
+// and exclusive otherwise. The lock lasts until limo exits
+func lockStore(cfg *config.Config) error {
+	if lock == nil {
+		var err error
+		if lock, err = model.AcquireLock(cfg.LockPath, !readOnly, options.wait); err != nil {
+			return err
+		}
+		if untilStopped {
+			return lock.KeepUntilStopped()
+		}
+	}
+	return nil
+}
+
 func getDatabase() (*gorm.DB, error) {
 	if db == nil {
 		cfg, err := getConfiguration()
 		if err != nil {
 			return nil, err
 		}
-		db, err = model.InitDB(cfg.DatabasePath, options.verbose)
+		if err = lockStore(cfg); err != nil {
+			return nil, err
+		}
+		db, err = model.OpenDB(cfg.GetDatabaseURL(), options.verbose)
 		if err != nil {
 			return nil, err
 		}
+		if err = migrateDatabase(cfg); err != nil {
+			db.Close()
+			db = nil
+			return nil, err
+		}
 	}
 	return db, nil
 }
 
+// migrateDatabase applies any pending migrations. Commands that only read share the lock,
+// so they take it exclusively while they migrate
+func migrateDatabase(cfg *config.Config) error {
+	pending, err := model.HasPendingMigrations(db)
+	if err != nil || !pending {
+		return err
+	}
+
+	if readOnly {
+		if err := lock.Relock(true, options.wait); err != nil {
+			return err
+		}
+	}
+	if _, _, err := model.Migrate(db, cfg.GetDatabaseURL()); err != nil {
+		return err
+	}
+	if readOnly {
+		return lock.Relock(false, options.wait)
+	}
+	return nil
+}
+
 func getIndex() (bleve.Index, error) {
 	if index == nil {
 		cfg, err := getConfiguration()
 		if err != nil {
 			return nil, err
 		}
-		index, err = model.InitIndex(cfg.IndexPath)
+		if err = lockStore(cfg); err != nil {
+			return nil, err
+		}
+		if readOnly {
+			index, err = model.OpenIndexReadOnly(cfg.IndexPath)
+		} else {
+			index, err = model.InitIndex(cfg.IndexPath)
+		}
 		if err != nil {
 			return nil, err
 		}
+
+		// Commands that only read can't rebuild the index, so they search it as it is
+		if !readOnly {
+			if err = rebuildOutdatedIndex(cfg); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return index, nil
 }
 
+// rebuildOutdatedIndex rebuilds the search index from the database if it was created with an older mapping,
+// so it searches the fields limo has added since
+func rebuildOutdatedIndex(cfg *config.Config) error {
+	current, err := model.IndexIsCurrent(index)
+	if err != nil || current {
+		return err
+	}
+
+	db, err := getDatabase()
+	if err != nil {
+		return err
+	}
+
+	getOutput().Info("Rebuilding the search index for this version of limo...")
+	index, err = model.RebuildIndex(index, cfg.IndexPath, db)
+	return err
+}
+
 func getOutput() output.Output {
 	o := output.ForName(options.output)
 	oc, err := getConfiguration()
@@ -125,6 +238,10 @@ func checkOneStar(name string, stars []model.Star) {
 	}
 }
 
+func findStarsByLanguageAndTag(db *gorm.DB, match string) ([]model.Star, error) {
+	return model.FindStarsByLanguageAndTag(db, match, options.language, options.tag, any)
+}
+
 func fatalOnError(err error) {
 	if err != nil {
 		getOutput().Fatal(err.Error())
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+// SaveSearchCmd saves a query as a saved search
+var SaveSearchCmd = &cobra.Command{
+	Use:   "save-search [name] [query]",
+	Short: "Save a search to use as a tag",
+	Long: `Save the query specified by [query] as a search named [name], which you can use anywhere you
+can use a tag by putting @ in front of its name. Its stars are found each time you use it, so it
+stays up to date without tagging. Leave out [query] to display the saved search, or use [--delete]
+to delete it. Leave out both to list your saved searches.`,
+	Example: fmt.Sprintf("  %s save-search recent-go \"language:go starred>30d\"\n  %s list stars -t @recent-go", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		if len(args) == 0 {
+			searches, err := model.FindSavedSearches(db)
+			fatalOnError(err)
+
+			for _, search := range searches {
+				output.Info(fmt.Sprintf("%s%s: %s", model.SavedSearchPrefix, search.Name, search.Query))
+			}
+			return
+		}
+
+		if len(args) == 1 || del {
+			search, err := model.FindSavedSearchByName(db, args[0])
+			fatalOnError(err)
+
+			if search == nil {
+				output.Fatal(fmt.Sprintf("Saved search '%s' not found", args[0]))
+			}
+
+			if del {
+				fatalOnError(search.Delete(db))
+				output.Info(fmt.Sprintf("Deleted saved search '%s%s'", model.SavedSearchPrefix, search.Name))
+			} else {
+				output.Info(search.Query)
+			}
+			return
+		}
+
+		search, created, err := model.SaveSearch(db, args[0], strings.Join(args[1:], " "))
+		fatalOnError(err)
+
+		if created {
+			output.Info(fmt.Sprintf("Saved search '%s%s'", model.SavedSearchPrefix, search.Name))
+		} else {
+			output.Info(fmt.Sprintf("Updated saved search '%s%s'", model.SavedSearchPrefix, search.Name))
+		}
+	},
+}
+
+func init() {
+	SaveSearchCmd.Flags().BoolVarP(&del, "delete", "d", false, "Delete the saved search")
+	RootCmd.AddCommand(SaveSearchCmd)
+}
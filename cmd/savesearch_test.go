@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveSearchCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, SaveSearchCmd.Use)
+}
+
+func TestSaveSearchCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, SaveSearchCmd.Short)
+}
+
+func TestSaveSearchCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, SaveSearchCmd.Long)
+}
+
+func TestSaveSearchCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, SaveSearchCmd.Run)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/server"
+	"github.com/spf13/cobra"
+)
+
+var addr = "127.0.0.1:8080"
+
+// ServeCmd serves your stars over a REST API
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve stars over a REST API",
+	Long: `Serve your stars and tags over a JSON REST API at [--addr].
+The API is unauthenticated, so bind it to a loopback address unless you mean to share it.
+It locks your database until you stop it, so other limo commands fail while it runs.`,
+	Example: fmt.Sprintf("  %s serve --addr 127.0.0.1:8080", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		index, err := getIndex()
+		fatalOnError(err)
+
+		mux := http.NewServeMux()
+		mux.Handle("/api/", server.New(db, index, serverUpdater, addr))
+
+		getOutput().Info(fmt.Sprintf("Serving on http://%s/api/", addr))
+		fatalOnError(http.ListenAndServe(addr, mux))
+	},
+}
+
+func init() {
+	ServeCmd.Flags().StringVarP(&addr, "addr", "a", "127.0.0.1:8080", "Address to listen on")
+	RootCmd.AddCommand(ServeCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, ServeCmd.Use)
+}
+
+func TestServeCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, ServeCmd.Short)
+}
+
+func TestServeCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, ServeCmd.Long)
+}
+
+func TestServeCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, ServeCmd.Run)
+}
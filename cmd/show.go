@@ -5,15 +5,18 @@ import (
 
 	"github.com/hoop33/limo/config"
 	"github.com/hoop33/limo/model"
+	"github.com/jinzhu/gorm"
 	"github.com/spf13/cobra"
 )
 
+var history = false
+
 // ShowCmd shows the version
 var ShowCmd = &cobra.Command{
 	Use:     "show <star>",
 	Short:   "Show a star's details",
-	Long:    "Show details about the star identified by <star>.",
-	Example: fmt.Sprintf("  %s show limo", config.ProgramName),
+	Long:    "Show details about the star identified by <star>. Use [--history] to show its daily stargazers, forks, and open issues, too.",
+	Example: fmt.Sprintf("  %s show limo\n  %s show limo --history", config.ProgramName, config.ProgramName),
 	Run: func(cmd *cobra.Command, args []string) {
 		output := getOutput()
 
@@ -33,12 +36,31 @@ var ShowCmd = &cobra.Command{
 				output.Error(err.Error())
 			} else {
 				output.Star(&star)
+				if history {
+					showHistory(db, &star)
+				}
 				output.Info("")
 			}
 		}
 	},
 }
 
+func showHistory(db *gorm.DB, star *model.Star) {
+	output := getOutput()
+
+	snapshots, err := star.FindSnapshots(db)
+	if err != nil {
+		output.Error(err.Error())
+		return
+	}
+
+	output.Info("History:")
+	for _, snapshot := range snapshots {
+		output.Snapshot(&snapshot)
+	}
+}
+
 func init() {
+	ShowCmd.Flags().BoolVarP(&history, "history", "H", false, "Show the star's history")
 	RootCmd.AddCommand(ShowCmd)
 }
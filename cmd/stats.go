@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+var top = 10
+
+// StatsCmd displays statistics about your stars
+var StatsCmd = &cobra.Command{
+	Use:     "stats",
+	Short:   "Display statistics about your stars",
+	Long:    "Display statistics about your stars: counts by service, language, and tag, and when you starred them.",
+	Example: fmt.Sprintf("  %s stats\n  %s stats --top 5 --output json", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		stats, err := model.FindStats(db, top)
+		fatalOnError(err)
+
+		getOutput().Stats(stats)
+	},
+}
+
+func init() {
+	StatsCmd.Flags().IntVar(&top, "top", 10, "Number of languages and tags to show")
+	RootCmd.AddCommand(StatsCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, StatsCmd.Use)
+}
+
+func TestStatsCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, StatsCmd.Short)
+}
+
+func TestStatsCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, StatsCmd.Long)
+}
+
+func TestStatsCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, StatsCmd.Run)
+}
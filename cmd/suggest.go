@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+var applyThreshold = 0.0
+var neighbors = 10
+var untagged = false
+
+// SuggestCmd suggests tags for stars
+var SuggestCmd = &cobra.Command{
+	Use:   "suggest [star]",
+	Short: "Suggest tags for stars",
+	Long: `Suggest tags for the star identified by [star], or for all your untagged stars with [--untagged],
+based on the tags of the most similar tagged stars. Each suggestion has a confidence from 0 to 1.
+Use [--apply] to add the suggestions with at least that confidence.`,
+	Example: fmt.Sprintf("  %s suggest limo\n  %s suggest --untagged --apply 0.8", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		if cmd.Flags().Changed("apply") && (applyThreshold <= 0 || applyThreshold > 1) {
+			output.Fatal(fmt.Sprintf("'%g' isn't a confidence greater than 0 and at most 1", applyThreshold))
+		}
+		if neighbors < 1 {
+			output.Fatal("You must consider at least one neighbor")
+		}
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		index, err := getIndex()
+		fatalOnError(err)
+
+		var stars []model.Star
+		if len(args) > 0 {
+			stars, err = model.FuzzyFindStarsByName(db, args[0])
+			fatalOnError(err)
+			checkOneStar(args[0], stars)
+		} else if untagged {
+			stars, err = model.FindUntaggedStars(db, "")
+			fatalOnError(err)
+		} else {
+			output.Fatal("You must specify a star or --untagged")
+		}
+
+		suggester, err := model.NewSuggester(db, index, neighbors)
+		fatalOnError(err)
+
+		totalSuggested, totalApplied, totalErrors := 0, 0, 0
+		op := newOperation()
+		for i := range stars {
+			star := &stars[i]
+			suggestions, err := suggester.Suggest(star)
+			if err != nil {
+				totalErrors++
+				output.Error(err.Error())
+				continue
+			}
+			if len(suggestions) == 0 {
+				continue
+			}
+
+			totalSuggested++
+			output.StarLine(star)
+			for _, suggestion := range suggestions {
+				output.Info(fmt.Sprintf("  %s (%.2f)", suggestion.Tag, suggestion.Confidence))
+			}
+
+			if applyThreshold > 0 {
+				applied, err := applySuggestions(op, star, suggestions)
+				totalApplied += len(applied)
+				suggester.Learn(star, applied...)
+				if err != nil {
+					totalErrors++
+					output.Error(err.Error())
+				}
+			}
+		}
+
+		if applyThreshold > 0 {
+			output.Info(fmt.Sprintf("Stars with suggestions: %d; Tags applied: %d; Errors: %d", totalSuggested, totalApplied, totalErrors))
+		} else {
+			output.Info(fmt.Sprintf("Stars with suggestions: %d; Errors: %d", totalSuggested, totalErrors))
+		}
+	},
+}
+
+// applySuggestions tags a star with the suggestions that meet the threshold, and returns the tags added
+func applySuggestions(op *model.Operation, star *model.Star, suggestions []model.Suggestion) ([]string, error) {
+	db, err := getDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := getIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := star.LoadTags(db); err != nil {
+		return nil, err
+	}
+
+	output := getOutput()
+
+	var applied []string
+	for _, suggestion := range suggestions {
+		if suggestion.Confidence < applyThreshold {
+			continue
+		}
+		tag, _, err := op.FindOrCreateTagByName(db, suggestion.Tag)
+		if err != nil {
+			return applied, err
+		}
+		if !star.HasTag(tag) {
+			if err := op.AddTag(db, star, tag); err != nil {
+				return applied, err
+			}
+			applied = append(applied, tag.Name)
+			output.Info(fmt.Sprintf("Added tag '%s'", tag.Name))
+		}
+	}
+
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	return applied, star.Index(index, db)
+}
+
+func init() {
+	SuggestCmd.Flags().Float64Var(&applyThreshold, "apply", 0, "Add suggested tags with at least this confidence (0 to 1)")
+	SuggestCmd.Flags().IntVar(&neighbors, "neighbors", 10, "Number of similar tagged stars to consider")
+	SuggestCmd.Flags().BoolVar(&untagged, "untagged", false, "Suggest tags for all untagged stars")
+	RootCmd.AddCommand(SuggestCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, SuggestCmd.Use)
+}
+
+func TestSuggestCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, SuggestCmd.Short)
+}
+
+func TestSuggestCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, SuggestCmd.Long)
+}
+
+func TestSuggestCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, SuggestCmd.Run)
+}
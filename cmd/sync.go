@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/hoop33/limo/service"
+	"github.com/spf13/cobra"
+)
+
+var direction = ""
+var dryRun = false
+
+var syncers = map[string]func(ctx context.Context){
+	"lists": syncLists,
+}
+
+// SyncCmd syncs tags with a service
+var SyncCmd = &cobra.Command{
+	Use:   "sync <lists>",
+	Short: "Sync tags with a service",
+	Long: `Sync your tags with the lists on the service specified by [--service] (default: github).
+Push creates lists from your tags, pull creates tags from your lists, and two-way does both.`,
+	Example: fmt.Sprintf("  %s sync lists\n  %s sync lists --direction pull --dry-run", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		var which string
+		if len(args) == 0 {
+			which = "lists"
+		} else {
+			which = args[0]
+		}
+
+		if fn, ok := syncers[which]; ok {
+			fn(ctx)
+		} else {
+			getOutput().Fatal(fmt.Sprintf("'%s' not valid", which))
+		}
+	},
+}
+
+func syncLists(ctx context.Context) {
+	output := getOutput()
+
+	cfg, err := getConfiguration()
+	fatalOnError(err)
+
+	dir := direction
+	if dir == "" {
+		dir = cfg.Sync.Direction
+	}
+	if dir != "push" && dir != "pull" && dir != "two-way" {
+		output.Fatal(fmt.Sprintf("Direction '%s' not valid (use push, pull, or two-way)", dir))
+	}
+
+	svc, err := getService("")
+	fatalOnError(err)
+
+	serviceName := service.Name(svc)
+	lister, ok := svc.(service.Lister)
+	if !ok {
+		output.Fatal(fmt.Sprintf("Service '%s' doesn't support lists", serviceName))
+	}
+
+	db, err := getDatabase()
+	fatalOnError(err)
+
+	dbSvc, _, err := model.FindOrCreateServiceByName(db, serviceName)
+	fatalOnError(err)
+
+	token := cfg.GetService(serviceName).Token
+
+	lists, err := lister.GetLists(ctx, token)
+	fatalOnError(err)
+
+	if dir == "pull" || dir == "two-way" {
+		pullLists(dbSvc, lists)
+	}
+
+	if dir == "push" || dir == "two-way" {
+		pushLists(ctx, lister, token, dbSvc, lists, dir == "two-way")
+	}
+}
+
+func pullLists(dbSvc *model.Service, lists []*model.List) {
+	output := getOutput()
+
+	db, err := getDatabase()
+	fatalOnError(err)
+
+	index, err := getIndex()
+	fatalOnError(err)
+
+	totalTagged, totalErrors := 0, 0
+	op := newOperation()
+
+	for _, list := range lists {
+		tag, err := findTagForList(op, list, dbSvc)
+		if err != nil {
+			totalErrors++
+			output.Error(err.Error())
+			continue
+		}
+
+		if tag == nil {
+			// Only happens for a dry run
+			output.Info(fmt.Sprintf("Create tag '%s'", list.Name))
+			tag = &model.Tag{Name: list.Name}
+		} else if !dryRun {
+			if _, err := model.CreateOrUpdateListMapping(db, tag, dbSvc, list.RemoteID); err != nil {
+				totalErrors++
+				output.Error(err.Error())
+				continue
+			}
+		}
+
+		for _, remoteID := range list.StarRemoteIDs {
+			star, err := model.FindStarByRemoteIDAndService(db, remoteID, dbSvc)
+			if err != nil {
+				// Not in the local database yet -- run update first
+				continue
+			}
+
+			if tag.ID != 0 {
+				if err := star.LoadTags(db); err != nil {
+					totalErrors++
+					output.Error(err.Error())
+					continue
+				}
+				if star.HasTag(tag) {
+					continue
+				}
+			}
+
+			output.StarLine(star)
+			output.Info(fmt.Sprintf("Tag '%s'", tag.Name))
+			if !dryRun {
+				if err := op.AddTag(db, star, tag); err != nil {
+					totalErrors++
+					output.Error(err.Error())
+					continue
+				}
+				if err := star.Index(index, db); err != nil {
+					totalErrors++
+					output.Error(err.Error())
+				}
+			}
+			totalTagged++
+		}
+	}
+
+	output.Info(fmt.Sprintf("Stars tagged: %d; Errors: %d", totalTagged, totalErrors))
+}
+
+func findTagForList(op *model.Operation, list *model.List, dbSvc *model.Service) (*model.Tag, error) {
+	db, err := getDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := model.FindListMappingByRemoteID(db, list.RemoteID, dbSvc)
+	if err != nil {
+		return nil, err
+	}
+	if mapping != nil {
+		return model.FindTagByID(db, mapping.TagID)
+	}
+
+	if dryRun {
+		return model.FindTagByName(db, list.Name)
+	}
+
+	tag, _, err := op.FindOrCreateTagByName(db, list.Name)
+	return tag, err
+}
+
+func pushLists(ctx context.Context, lister service.Lister, token string, dbSvc *model.Service, lists []*model.List, additive bool) {
+	output := getOutput()
+
+	db, err := getDatabase()
+	fatalOnError(err)
+
+	plan, err := model.PlanListPush(db, dbSvc, lists, additive)
+	fatalOnError(err)
+
+	names := make(map[string]string)
+	for _, list := range lists {
+		names[list.RemoteID] = list.Name
+	}
+
+	totalCreated, totalMoved, totalErrors := 0, 0, 0
+
+	if !dryRun {
+		for _, mapping := range plan.Adopts {
+			tag, err := model.FindTagByID(db, mapping.TagID)
+			if err == nil {
+				_, err = model.CreateOrUpdateListMapping(db, tag, dbSvc, mapping.RemoteID)
+			}
+			if err != nil {
+				totalErrors++
+				output.Error(err.Error())
+			}
+		}
+	}
+
+	// Lists must exist before we can move stars into them
+	created := make(map[string]string)
+	pending := make(map[string]bool)
+	for _, tag := range plan.Creates {
+		pendingID := model.PendingListID(&tag)
+		names[pendingID] = tag.Name
+		pending[pendingID] = true
+
+		output.Info(fmt.Sprintf("Create list '%s'", tag.Name))
+		if !dryRun {
+			list, err := lister.CreateList(ctx, token, tag.Name)
+			if err != nil {
+				totalErrors++
+				output.Error(err.Error())
+				continue
+			}
+			created[pendingID] = list.RemoteID
+			if _, err := model.CreateOrUpdateListMapping(db, &tag, dbSvc, list.RemoteID); err != nil {
+				totalErrors++
+				output.Error(err.Error())
+			}
+		}
+		totalCreated++
+	}
+
+	for _, move := range plan.Moves {
+		output.StarLine(move.Star)
+		output.Info(fmt.Sprintf("Move from [%s] to [%s]", listNames(move.From, names), listNames(move.To, names)))
+		if !dryRun {
+			var listIDs []string
+			for _, listID := range move.To {
+				if remoteID, ok := created[listID]; ok {
+					listIDs = append(listIDs, remoteID)
+				} else if !pending[listID] {
+					listIDs = append(listIDs, listID)
+				}
+			}
+			if err := lister.SetStarLists(ctx, token, move.Star, listIDs); err != nil {
+				totalErrors++
+				output.Error(err.Error())
+				continue
+			}
+		}
+		totalMoved++
+	}
+
+	output.Info(fmt.Sprintf("Lists created: %d; Stars moved: %d; Errors: %d", totalCreated, totalMoved, totalErrors))
+}
+
+func listNames(listIDs []string, names map[string]string) string {
+	values := make([]string, 0, len(listIDs))
+	for _, listID := range listIDs {
+		values = append(values, names[listID])
+	}
+	return strings.Join(values, ", ")
+}
+
+func init() {
+	SyncCmd.Flags().StringVarP(&direction, "direction", "d", "", "Direction to sync: push, pull, or two-way (default: from configuration)")
+	SyncCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Display what would change without changing anything")
+	RootCmd.AddCommand(SyncCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, SyncCmd.Use)
+}
+
+func TestSyncCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, SyncCmd.Short)
+}
+
+func TestSyncCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, SyncCmd.Long)
+}
+
+func TestSyncCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, SyncCmd.Run)
+}
@@ -10,10 +10,11 @@ import (
 
 // TagCmd tags a star
 var TagCmd = &cobra.Command{
-	Use:     "tag <star> <tag>...",
-	Short:   "Tag a star",
-	Long:    "Tag the star identified by <star> with the tags specified by <tag>, creating tags as necessary.",
-	Example: fmt.Sprintf("  %s tag limo git cli", config.ProgramName),
+	Use:   "tag <star> <tag>...",
+	Short: "Tag a star",
+	Long: `Tag the star identified by <star> with the tags specified by <tag>, creating tags as necessary.
+To tag a star named like one of tag's commands, such as merge or alias, put -- before it.`,
+	Example: fmt.Sprintf("  %s tag limo git cli\n  %s tag -- merge git", config.ProgramName, config.ProgramName),
 	Run: func(cmd *cobra.Command, args []string) {
 		output := getOutput()
 
@@ -30,12 +31,13 @@ var TagCmd = &cobra.Command{
 		checkOneStar(args[0], stars)
 
 		output.StarLine(&stars[0])
+		op := newOperation()
 		for _, tagName := range args[1:] {
-			tag, _, err := model.FindOrCreateTagByName(db, tagName)
+			tag, _, err := op.FindOrCreateTagByName(db, tagName)
 			if err != nil {
 				output.Error(err.Error())
 			} else {
-				err = stars[0].AddTag(db, tag)
+				err = op.AddTag(db, &stars[0], tag)
 				if err != nil {
 					output.Error(err.Error())
 				} else {
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+var olderThan string
+
+var restorers = map[string]func([]string){
+	"star": restoreStar,
+	"tag":  restoreTag,
+}
+
+// TrashCmd manages deleted stars and tags
+var TrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted stars and tags",
+	Long:  "List, restore, or permanently purge the stars and tags you've deleted.",
+	Example: fmt.Sprintf("  %s trash list\n  %s trash restore tag frameworks\n  %s trash purge --older-than 30d",
+		config.ProgramName, config.ProgramName, config.ProgramName),
+}
+
+// TrashListCmd lists deleted stars and tags
+var TrashListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List deleted stars and tags",
+	Long:    "List the stars and tags in the trash, most recently deleted first.",
+	Example: fmt.Sprintf("  %s trash list", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		stars, err := model.FindTrashedStars(db)
+		fatalOnError(err)
+
+		tags, err := model.FindTrashedTags(db)
+		fatalOnError(err)
+
+		if len(stars) == 0 && len(tags) == 0 {
+			output.Info("The trash is empty")
+			return
+		}
+
+		for _, star := range stars {
+			output.Info(fmt.Sprintf("star %s (deleted %s)", *star.FullName, star.DeletedAt.Format(time.UnixDate)))
+		}
+		for _, tag := range tags {
+			output.Info(fmt.Sprintf("tag %s (deleted %s)", tag.Name, tag.DeletedAt.Format(time.UnixDate)))
+		}
+	},
+}
+
+// TrashRestoreCmd restores deleted stars or tags
+var TrashRestoreCmd = &cobra.Command{
+	Use:   "restore <star|tag> <name>...",
+	Short: "Restore deleted stars or tags",
+	Long: `Restore stars or tags from the trash. Restoring a tag puts it back on the stars it was on
+when you deleted it, and restores its aliases. Restoring a star only restores it to your local
+database -- it doesn't star the repository again on its service.`,
+	Example: fmt.Sprintf("  %s trash restore tag frameworks\n  %s trash restore star hoop33/limo",
+		config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			getOutput().Fatal("You must specify star or tag and values")
+		}
+
+		if fn, ok := restorers[args[0]]; ok {
+			fn(args[1:])
+		} else {
+			getOutput().Fatal(fmt.Sprintf("'%s' not valid", args[0]))
+		}
+	},
+}
+
+// TrashPurgeCmd permanently deletes stars and tags from the trash
+var TrashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete stars and tags from the trash",
+	Long: `Permanently delete stars and tags from the trash. Use --older-than with a time ago like 30d
+to purge only what you deleted before then.`,
+	Example: fmt.Sprintf("  %s trash purge --older-than 30d", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		before := time.Now()
+		if olderThan != "" {
+			var parseErr error
+			before, parseErr = model.ParseTimeAgo(olderThan, before)
+			fatalOnError(parseErr)
+		}
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		index, err := getIndex()
+		fatalOnError(err)
+
+		stars, tags, err := model.PurgeTrash(db, before)
+		fatalOnError(err)
+
+		totalErrors := 0
+		for _, star := range stars {
+			if err := index.Delete(fmt.Sprintf("%d", star.ID)); err != nil {
+				totalErrors++
+				output.Error(err.Error())
+			}
+		}
+
+		output.Info(fmt.Sprintf("Purged stars: %d; Tags: %d; Errors: %d", len(stars), len(tags), totalErrors))
+	},
+}
+
+func restoreStar(values []string) {
+	output := getOutput()
+
+	db, err := getDatabase()
+	fatalOnError(err)
+
+	index, err := getIndex()
+	fatalOnError(err)
+
+	for _, value := range values {
+		star, err := model.FindTrashedStarByName(db, value)
+		if err == nil {
+			err = star.Restore(db)
+		}
+		if err == nil {
+			err = star.Index(index, db)
+		}
+		if err != nil {
+			output.Error(err.Error())
+		} else {
+			output.Info(fmt.Sprintf("Restored star '%s'", *star.FullName))
+		}
+	}
+}
+
+func restoreTag(values []string) {
+	output := getOutput()
+
+	db, err := getDatabase()
+	fatalOnError(err)
+
+	index, err := getIndex()
+	fatalOnError(err)
+
+	for _, value := range values {
+		tag, err := model.FindTrashedTagByName(db, value)
+		if err != nil {
+			output.Error(err.Error())
+			continue
+		}
+
+		starIDs, err := tag.Restore(db)
+		if err != nil {
+			output.Error(err.Error())
+			continue
+		}
+
+		totalErrors := 0
+		for _, ID := range starIDs {
+			star, err := model.FindStarByID(db, ID)
+			if err == nil {
+				err = star.Index(index, db)
+			}
+			if err != nil {
+				totalErrors++
+				output.Error(err.Error())
+			}
+		}
+
+		output.Info(fmt.Sprintf("Restored tag '%s'; Stars updated: %d; Errors: %d",
+			tag.Name, len(starIDs)-totalErrors, totalErrors))
+	}
+}
+
+func init() {
+	TrashPurgeCmd.Flags().StringVar(&olderThan, "older-than", "", "Purge only what was deleted before a time ago, like 30d (default: everything)")
+	TrashCmd.AddCommand(TrashListCmd)
+	TrashCmd.AddCommand(TrashRestoreCmd)
+	TrashCmd.AddCommand(TrashPurgeCmd)
+	RootCmd.AddCommand(TrashCmd)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, TrashCmd.Use)
+}
+
+func TestTrashCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, TrashCmd.Short)
+}
+
+func TestTrashCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, TrashCmd.Long)
+}
+func TestTrashListCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, TrashListCmd.Use)
+}
+
+func TestTrashListCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, TrashListCmd.Short)
+}
+
+func TestTrashListCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, TrashListCmd.Long)
+}
+
+func TestTrashListCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, TrashListCmd.Run)
+}
+func TestTrashRestoreCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, TrashRestoreCmd.Use)
+}
+
+func TestTrashRestoreCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, TrashRestoreCmd.Short)
+}
+
+func TestTrashRestoreCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, TrashRestoreCmd.Long)
+}
+
+func TestTrashRestoreCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, TrashRestoreCmd.Run)
+}
+func TestTrashPurgeCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, TrashPurgeCmd.Use)
+}
+
+func TestTrashPurgeCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, TrashPurgeCmd.Short)
+}
+
+func TestTrashPurgeCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, TrashPurgeCmd.Long)
+}
+
+func TestTrashPurgeCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, TrashPurgeCmd.Run)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+var window = "30d"
+
+// TrendsCmd shows the fastest growing and declining stars
+var TrendsCmd = &cobra.Command{
+	Use:   "trends",
+	Short: "Show your fastest growing and declining stars",
+	Long: `Show the stars in your collection whose stargazers grew or declined the most over [--window].
+Limo records each star's stargazers, forks, and open issues once a day when you update, so trends
+appear once you've updated on more than one day.`,
+	Example: fmt.Sprintf("  %s trends --window 2w --top 5", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		since, err := model.ParseTimeAgo(window, time.Now())
+		fatalOnError(err)
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		trends, err := model.FindTrends(db, since)
+		fatalOnError(err)
+
+		var growing, declining []model.Trend
+		for _, trend := range trends {
+			if trend.Change > 0 && len(growing) < top {
+				growing = append(growing, trend)
+			}
+		}
+		for i := len(trends) - 1; i >= 0 && len(declining) < top; i-- {
+			if trends[i].Change < 0 {
+				declining = append(declining, trends[i])
+			}
+		}
+
+		if len(growing) == 0 && len(declining) == 0 {
+			output.Info(fmt.Sprintf("No changes in the last %s", window))
+			return
+		}
+
+		if len(growing) > 0 {
+			output.Info("Fastest growing:")
+			for _, trend := range growing {
+				output.Trend(&trend)
+			}
+		}
+		if len(declining) > 0 {
+			output.Info("Declining:")
+			for _, trend := range declining {
+				output.Trend(&trend)
+			}
+		}
+	},
+}
+
+func init() {
+	TrendsCmd.Flags().StringVarP(&window, "window", "w", "30d", "How far back to look, like 30d, 2w, 6m, or 1y")
+	TrendsCmd.Flags().IntVar(&top, "top", 10, "Number of stars to show in each list")
+	RootCmd.AddCommand(TrendsCmd)
+}
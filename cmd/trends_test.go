@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrendsCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, TrendsCmd.Use)
+}
+
+func TestTrendsCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, TrendsCmd.Short)
+}
+
+func TestTrendsCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, TrendsCmd.Long)
+}
+
+func TestTrendsCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, TrendsCmd.Run)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+	"github.com/spf13/cobra"
+)
+
+// UndoCmd undoes the most recent changes to your local database
+var UndoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Undo your most recent changes",
+	Long: `Undo the last [n] (default: 1) commands that changed your local database and haven't been undone,
+most recent first. Run 'history' to see what they are.`,
+	Example: fmt.Sprintf("  %s undo\n  %s undo 3", config.ProgramName, config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		output := getOutput()
+
+		n := 1
+		if len(args) > 0 {
+			var parseErr error
+			if n, parseErr = strconv.Atoi(args[0]); parseErr != nil || n < 1 {
+				output.Fatal(fmt.Sprintf("'%s' isn't a number of commands to undo", args[0]))
+			}
+		}
+
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		index, err := getIndex()
+		fatalOnError(err)
+
+		ops, err := model.FindUndoableOperations(db, n)
+		fatalOnError(err)
+
+		if len(ops) == 0 {
+			output.Info("Nothing to undo")
+			return
+		}
+
+		for _, op := range ops {
+			starIDs, err := op.Undo(db)
+			fatalOnError(err)
+
+			totalErrors := 0
+			for _, ID := range starIDs {
+				star, err := model.FindStarByID(db, ID)
+				if err == nil {
+					err = star.Index(index, db)
+				}
+				if err != nil {
+					totalErrors++
+					output.Error(err.Error())
+				}
+			}
+
+			output.Info(fmt.Sprintf("Undid '%s'; Changes: %d; Errors: %d", op.Command, len(op.Steps), totalErrors))
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(UndoCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUndoCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, UndoCmd.Use)
+}
+
+func TestUndoCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, UndoCmd.Short)
+}
+
+func TestUndoCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, UndoCmd.Long)
+}
+
+func TestUndoCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, UndoCmd.Run)
+}
@@ -31,10 +31,11 @@ var UntagCmd = &cobra.Command{
 
 		output.StarLine(&stars[0])
 
+		op := newOperation()
 		if len(args) == 1 {
 			// Untag all
-			fatalOnError(stars[0].RemoveAllTags(db))
-			output.Info(fmt.Sprintf("Removed all tags"))
+			fatalOnError(op.RemoveAllTags(db, &stars[0]))
+			output.Info(fmt.Sprintf("Removed all tags (run '%s undo' to put them back)", config.ProgramName))
 		} else {
 			fatalOnError(stars[0].LoadTags(db))
 
@@ -47,7 +48,7 @@ var UntagCmd = &cobra.Command{
 				} else if !stars[0].HasTag(tag) {
 					output.Error(fmt.Sprintf("'%s' isn't tagged with '%s'", *stars[0].FullName, tagName))
 				} else {
-					err = stars[0].RemoveTag(db, tag)
+					err = op.RemoveTag(db, &stars[0], tag)
 					if err != nil {
 						output.Error(err.Error())
 					} else {
@@ -8,85 +8,201 @@ import (
 	"github.com/hoop33/limo/config"
 	"github.com/hoop33/limo/model"
 	"github.com/hoop33/limo/service"
+	"github.com/jinzhu/gorm"
 	"github.com/spf13/cobra"
 )
 
+var autoTagRules = false
+var autoTagTopics = false
+
+// updateTotals counts what an update did
+type updateTotals struct {
+	created  int
+	updated  int
+	errors   int
+	tagged   int
+	untagged int
+	ruled    int
+	run      *model.UpdateRun
+}
+
 // UpdateCmd updates your stars from a remote service
 var UpdateCmd = &cobra.Command{
-	Use:     "update",
-	Short:   "Update stars from a service",
-	Long:    "Update your local database with your stars from the service specified by [--service] (default: github).",
-	Example: fmt.Sprintf("  %s update", config.ProgramName),
+	Use:   "update",
+	Short: "Update stars from a service",
+	Long: `Update your local database with your stars from the service specified by [--service] (default: github).
+With [--auto-tag-topics], also tag your stars from their topics, and remove topic tags for topics they no longer have.
+With [--auto-tag-rules], also tag new stars using the rules in your configuration.`,
+	Example: fmt.Sprintf("  %s update\n  %s update --auto-tag-topics --auto-tag-rules", config.ProgramName, config.ProgramName),
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
-
-		// Get configuration
-		cfg, err := getConfiguration()
-		fatalOnError(err)
-
-		// Get the database
-		db, err := getDatabase()
-		fatalOnError(err)
-
-		// Get the search index
-		index, err := getIndex()
-		fatalOnError(err)
-
-		// Get the specified service
-		svc, err := getService("")
+		totals, err := updateStars(context.Background(), "")
 		fatalOnError(err)
 
-		// Get the database record for the specified service
-		serviceName := service.Name(svc)
-		dbSvc, _, err := model.FindOrCreateServiceByName(db, serviceName)
-		fatalOnError(err)
-
-		startTime := time.Now()
-
-		// Create a channel to receive stars, since service can page
-		starChan := make(chan *model.StarResult, 20)
-
-		// Get the stars for the authenticated user
-		go svc.GetStars(ctx, starChan, cfg.GetService(serviceName).Token, "")
-
 		output := getOutput()
+		output.Info(fmt.Sprintf("\nCreated: %d; Updated: %d; Errors: %d", totals.created, totals.updated, totals.errors))
+		if autoTagTopics {
+			output.Info(fmt.Sprintf("Topic tags added: %d; Topic tags removed: %d", totals.tagged, totals.untagged))
+		}
+		if autoTagRules {
+			output.Info(fmt.Sprintf("Rule tags added: %d", totals.ruled))
+		}
+		if summary := changeSummary(totals.run); summary != "" {
+			output.Info(fmt.Sprintf("%s (run '%s changes' for details)", summary, config.ProgramName))
+		}
+	},
+}
 
-		totalCreated, totalUpdated, totalErrors := 0, 0, 0
-
-		for starResult := range starChan {
-			if starResult.Error != nil {
-				totalErrors++
-				output.Error(starResult.Error.Error())
+// updateStars updates the database and search index with the stars from the named service,
+// or from [--service] if name is empty
+func updateStars(ctx context.Context, name string) (*updateTotals, error) {
+	// Get configuration
+	cfg, err := getConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the database
+	db, err := getDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the search index
+	index, err := getIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the specified service
+	svc, err := getService(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the database record for the specified service
+	serviceName := service.Name(svc)
+	dbSvc, _, err := model.FindOrCreateServiceByName(db, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+
+	// Record what this update changes
+	run, err := model.StartUpdateRun(db, dbSvc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a channel to receive stars, since service can page
+	starChan := make(chan *model.StarResult, 20)
+
+	// Get the stars for the authenticated user
+	go svc.GetStars(ctx, starChan, cfg.GetService(serviceName).Token, "")
+
+	output := getOutput()
+
+	tagger := &model.TopicTagger{
+		Allow:  cfg.Topics.Allow,
+		Deny:   cfg.Topics.Deny,
+		Rename: cfg.Topics.Rename,
+	}
+
+	var rules []*model.Rule
+	if autoTagRules {
+		if rules, err = model.ParseRules(cfg.Rules); err != nil {
+			return nil, err
+		}
+	}
+
+	totals := &updateTotals{}
+	op := newOperation()
+
+	// The update is complete if it saved every star the service listed
+	complete := true
+	for starResult := range starChan {
+		if starResult.Error != nil {
+			complete = false
+			totals.errors++
+			output.Error(starResult.Error.Error())
+		} else {
+			created, err := run.CreateOrUpdateStar(db, starResult.Star, dbSvc)
+			if err != nil {
+				complete = false
+				totals.errors++
+				output.Error(fmt.Sprintf("Error %s: %s", *starResult.Star.FullName, err.Error()))
 			} else {
-				created, err := model.CreateOrUpdateStar(db, starResult.Star, dbSvc)
-				if err != nil {
-					totalErrors++
-					output.Error(fmt.Sprintf("Error %s: %s", *starResult.Star.FullName, err.Error()))
+				if created {
+					totals.created++
 				} else {
-					if created {
-						totalCreated++
-					} else {
-						totalUpdated++
+					totals.updated++
+				}
+				if autoTagTopics {
+					added, removed, err := tagger.Apply(db, op, starResult.Star)
+					totals.tagged += len(added)
+					totals.untagged += len(removed)
+					if err != nil {
+						totals.errors++
+						output.Error(fmt.Sprintf("Error %s: %s", *starResult.Star.FullName, err.Error()))
 					}
-					err = starResult.Star.Index(index, db)
+				}
+				if created && len(rules) > 0 {
+					ruled, err := applyRules(db, op, rules, *starResult.Star)
+					totals.ruled += ruled
 					if err != nil {
-						totalErrors++
+						totals.errors++
 						output.Error(fmt.Sprintf("Error %s: %s", *starResult.Star.FullName, err.Error()))
 					}
-					output.Tick()
 				}
+				err = starResult.Star.Index(index, db)
+				if err != nil {
+					totals.errors++
+					output.Error(fmt.Sprintf("Error %s: %s", *starResult.Star.FullName, err.Error()))
+				}
+				output.Tick()
 			}
 		}
-
-		if totalCreated > 0 || totalUpdated > 0 {
-			dbSvc.LastSuccess = startTime
-			fatalOnError(db.Save(dbSvc).Error)
+	}
+
+	// A stopped update doesn't count as a success, or pruning would delete the stars it didn't get to
+	run.Complete = complete && ctx.Err() == nil
+	successful := run.Complete && (totals.created > 0 || totals.updated > 0)
+	run.Errors = totals.errors
+	if err := run.Finish(db, dbSvc.LastSuccess, successful); err != nil {
+		return totals, err
+	}
+	totals.run = run
+
+	if successful {
+		dbSvc.LastSuccess = startTime
+		if err := db.Save(dbSvc).Error; err != nil {
+			return totals, err
 		}
+	}
 
-		output.Info(fmt.Sprintf("\nCreated: %d; Updated: %d; Errors: %d", totalCreated, totalUpdated, totalErrors))
-	},
+	return totals, nil
+}
+
+// applyRules tags a star using rules and returns the number of tags added
+func applyRules(db *gorm.DB, op *model.Operation, rules []*model.Rule, star model.Star) (int, error) {
+	matches, err := model.PlanRules(db, rules, []model.Star{star})
+	if err != nil || len(matches) == 0 {
+		return 0, err
+	}
+	return len(matches[0].Tags), matches[0].Apply(db, op)
+}
+
+// serverUpdater updates stars for the server
+func serverUpdater(ctx context.Context, name string) (int, int, int, error) {
+	totals, err := updateStars(ctx, name)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return totals.created, totals.updated, totals.errors, nil
 }
 
 func init() {
+	UpdateCmd.Flags().BoolVar(&autoTagRules, "auto-tag-rules", false, "Tag new stars using your rules")
+	UpdateCmd.Flags().BoolVar(&autoTagTopics, "auto-tag-topics", false, "Tag stars from their topics")
 	RootCmd.AddCommand(UpdateCmd)
 }
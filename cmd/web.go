@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/server"
+	"github.com/spf13/cobra"
+)
+
+// WebCmd serves the web UI
+var WebCmd = &cobra.Command{
+	Use:   "web",
+	Short: "Browse and tag stars in your web browser",
+	Long: `Serve a web UI for browsing, searching, and tagging your stars at [--addr].
+The UI is built into limo and uses only your local database and search index, so it works offline.
+It locks your database until you stop it, so other limo commands fail while it runs.`,
+	Example: fmt.Sprintf("  %s web --addr 127.0.0.1:8080", config.ProgramName),
+	Run: func(cmd *cobra.Command, args []string) {
+		db, err := getDatabase()
+		fatalOnError(err)
+
+		index, err := getIndex()
+		fatalOnError(err)
+
+		getOutput().Info(fmt.Sprintf("Serving on http://%s/", addr))
+		fatalOnError(http.ListenAndServe(addr, server.Web(server.New(db, index, serverUpdater, addr))))
+	},
+}
+
+func init() {
+	WebCmd.Flags().StringVarP(&addr, "addr", "a", "127.0.0.1:8080", "Address to listen on")
+	RootCmd.AddCommand(WebCmd)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebCmdHasUse(t *testing.T) {
+	assert.NotEmpty(t, WebCmd.Use)
+}
+
+func TestWebCmdHasShort(t *testing.T) {
+	assert.NotEmpty(t, WebCmd.Short)
+}
+
+func TestWebCmdHasLong(t *testing.T) {
+	assert.NotEmpty(t, WebCmd.Long)
+}
+
+func TestWebCmdHasRun(t *testing.T) {
+	assert.NotEmpty(t, WebCmd.Run)
+}
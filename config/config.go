@@ -27,12 +27,40 @@ type OutputConfig struct {
 	SpinnerColor    string `yaml:"spinnerColor"`
 }
 
+// SyncConfig contains configuration information for syncing with a service
+type SyncConfig struct {
+	Direction string `yaml:"direction"`
+}
+
+// TopicsConfig contains configuration information for tagging stars from their topics
+type TopicsConfig struct {
+	Allow  []string          `yaml:"allow"`
+	Deny   []string          `yaml:"deny"`
+	Rename map[string]string `yaml:"rename"`
+}
+
+// TrendingConfig contains the defaults for listing trending repositories
+type TrendingConfig struct {
+	Since    string `yaml:"since"`
+	By       string `yaml:"by"`
+	Sort     string `yaml:"sort"`
+	Pages    int    `yaml:"pages"`
+	MinStars int    `yaml:"minStars"`
+}
+
 // Config contains configuration information
 type Config struct {
-	DatabasePath string                    `yaml:"databasePath"`
-	IndexPath    string                    `yaml:"indexPath"`
-	Services     map[string]*ServiceConfig `yaml:"services"`
-	Outputs      map[string]*OutputConfig  `yaml:"outputs"`
+	DatabasePath  string                    `yaml:"databasePath"`
+	DatabaseURL   string                    `yaml:"databaseURL"`
+	IndexPath     string                    `yaml:"indexPath"`
+	LockPath      string                    `yaml:"lockPath"`
+	WorkspacePath string                    `yaml:"workspacePath"`
+	Services      map[string]*ServiceConfig `yaml:"services"`
+	Outputs       map[string]*OutputConfig  `yaml:"outputs"`
+	Sync          SyncConfig                `yaml:"sync"`
+	Topics        TopicsConfig              `yaml:"topics"`
+	Trending      TrendingConfig            `yaml:"trending"`
+	Rules         []string                  `yaml:"rules"`
 }
 
 // GetService returns the configuration information for a service
@@ -49,6 +77,15 @@ func (cfg *Config) GetService(name string) *ServiceConfig {
 	return service
 }
 
+// GetDatabaseURL returns the database to use -- databaseURL if it's set, like
+// postgres://limo@localhost/limo, or else the SQLite database at databasePath
+func (cfg *Config) GetDatabaseURL() string {
+	if cfg.DatabaseURL != "" {
+		return cfg.DatabaseURL
+	}
+	return cfg.DatabasePath
+}
+
 // GetOutput returns the configuration information for an output
 func (cfg *Config) GetOutput(name string) *OutputConfig {
 	if cfg.Outputs == nil {
@@ -90,6 +127,35 @@ func ReadConfig() (*Config, error) {
 	if cfg.IndexPath == "" {
 		cfg.IndexPath = path.Join(configDirectoryPath, fmt.Sprintf("%s.idx", ProgramName))
 	}
+
+	// Set default lock path
+	if cfg.LockPath == "" {
+		cfg.LockPath = path.Join(configDirectoryPath, fmt.Sprintf("%s.lock", ProgramName))
+	}
+
+	// Set default workspace path for cloned stars
+	if cfg.WorkspacePath == "" {
+		cfg.WorkspacePath = path.Join(configDirectoryPath, "workspace")
+	}
+
+	// Set default sync direction
+	if cfg.Sync.Direction == "" {
+		cfg.Sync.Direction = "push"
+	}
+
+	// Set default trending: the most-starred repositories created in the last week
+	if cfg.Trending.Since == "" {
+		cfg.Trending.Since = "week"
+	}
+	if cfg.Trending.By == "" {
+		cfg.Trending.By = "created"
+	}
+	if cfg.Trending.Sort == "" {
+		cfg.Trending.Sort = "stars"
+	}
+	if cfg.Trending.Pages == 0 {
+		cfg.Trending.Pages = 1
+	}
 	return &cfg, nil
 }
 
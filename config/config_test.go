@@ -33,6 +33,24 @@ func TestDefaultIndexPathIsSetWhenConfigIsEmpty(t *testing.T) {
 	assert.NotEmpty(t, config.IndexPath)
 }
 
+func TestDefaultLockPathIsSetWhenConfigIsEmpty(t *testing.T) {
+	rmdirConfig()
+	config, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, config.LockPath)
+}
+
+func TestDefaultTrendingIsSetWhenConfigIsEmpty(t *testing.T) {
+	rmdirConfig()
+	config, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, TrendingConfig{Since: "week", By: "created", Sort: "stars", Pages: 1}, config.Trending)
+}
+
 func TestCanSetDatabasePath(t *testing.T) {
 	rmdirConfig()
 	config, err := ReadConfig()
@@ -125,6 +143,43 @@ func TestReadConfigDoesNotPanicForMalformedConfigurationFile(t *testing.T) {
 	rmdirConfig()
 }
 
+func TestReadConfigReadsTopics(t *testing.T) {
+	rmdirConfig()
+	mkdirConfig()
+
+	contents := `topics:
+  deny: [hacktoberfest]
+  rename:
+    golang: go
+`
+	err := ioutil.WriteFile(fmt.Sprintf("%s/limo.yaml", configDirectoryPath), []byte(contents), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"hacktoberfest"}, cfg.Topics.Deny)
+	assert.Equal(t, "go", cfg.Topics.Rename["golang"])
+
+	rmdirConfig()
+}
+
+func TestGetDatabaseURLDefaultsToDatabasePath(t *testing.T) {
+	rmdirConfig()
+	config, err := ReadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, config.DatabasePath, config.GetDatabaseURL())
+
+	config.DatabaseURL = "postgres://limo@localhost/limo"
+	assert.Equal(t, "postgres://limo@localhost/limo", config.GetDatabaseURL())
+}
+
 func mkdirConfig() {
 	if err := os.MkdirAll(configDirectoryPath, 0700); err != nil {
 		panic(err)
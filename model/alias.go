@@ -0,0 +1,70 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TagAlias is another name for a tag, so tagging with the alias uses the tag instead of creating a new one
+type TagAlias struct {
+	gorm.Model
+	Name  string
+	TagID uint
+}
+
+// findTagByAlias finds the tag an alias points to
+func findTagByAlias(db *gorm.DB, name string) (*Tag, error) {
+	var alias TagAlias
+	if db.Where("lower(name) = ?", strings.ToLower(name)).First(&alias).RecordNotFound() {
+		return nil, db.Error
+	}
+	return FindTagByID(db, alias.TagID)
+}
+
+// FindAliases finds the aliases for a tag
+func (tag *Tag) FindAliases(db *gorm.DB) ([]TagAlias, error) {
+	var aliases []TagAlias
+	db.Where("tag_id = ?", tag.ID).Order("name").Find(&aliases)
+	return aliases, db.Error
+}
+
+// AddAlias adds an alias for a tag -- the alias must not already be a tag or another tag's alias
+func (tag *Tag) AddAlias(db *gorm.DB, name string) error {
+	name = CleanTagName(name)
+	if name == "" {
+		return errors.New("you must specify an alias")
+	}
+
+	existing, err := FindTagByName(db, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if existing.ID == tag.ID {
+			return fmt.Errorf("'%s' already refers to tag '%s'", name, tag.Name)
+		}
+		return fmt.Errorf("tag '%s' already exists", existing.Name)
+	}
+
+	return db.Create(&TagAlias{
+		Name:  name,
+		TagID: tag.ID,
+	}).Error
+}
+
+// RemoveAlias removes an alias from a tag
+func (tag *Tag) RemoveAlias(db *gorm.DB, name string) error {
+	var alias TagAlias
+	if db.Where("tag_id = ? AND lower(name) = ?", tag.ID, strings.ToLower(CleanTagName(name))).First(&alias).RecordNotFound() {
+		return fmt.Errorf("tag '%s' has no alias '%s'", tag.Name, name)
+	}
+	return db.Delete(&alias).Error
+}
+
+// removeAliases removes aliases with the specified name
+func removeAliases(db *gorm.DB, name string) error {
+	return db.Where("lower(name) = ?", strings.ToLower(name)).Delete(&TagAlias{}).Error
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindTagByNameShouldResolveAlias(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "javascript")
+	assert.Nil(t, err)
+	assert.Nil(t, tag.AddAlias(db, "js"))
+
+	found, err := FindTagByName(db, "JS")
+	assert.Nil(t, err)
+	assert.NotNil(t, found)
+	assert.Equal(t, tag.ID, found.ID)
+
+	found, created, err := FindOrCreateTagByName(db, "js")
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, tag.ID, found.ID)
+
+	count, err := CountTags(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestFindOrCreateTagByNameShouldResolveAliasedParent(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "lang/javascript")
+	assert.Nil(t, err)
+	assert.Nil(t, tag.AddAlias(db, "js"))
+
+	child, created, err := FindOrCreateTagByName(db, "js/react")
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "lang/javascript/react", child.Name)
+	assert.Equal(t, tag.ID, *child.ParentID)
+}
+
+func TestAddAliasShouldRejectExistingNames(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "javascript")
+	assert.Nil(t, err)
+	other, _, err := FindOrCreateTagByName(db, "typescript")
+	assert.Nil(t, err)
+	assert.Nil(t, other.AddAlias(db, "ts"))
+
+	err = tag.AddAlias(db, "TypeScript")
+	assert.NotNil(t, err)
+	assert.Equal(t, "tag 'typescript' already exists", err.Error())
+
+	err = tag.AddAlias(db, "ts")
+	assert.NotNil(t, err)
+	assert.Equal(t, "tag 'typescript' already exists", err.Error())
+
+	err = other.AddAlias(db, "ts")
+	assert.NotNil(t, err)
+	assert.Equal(t, "'ts' already refers to tag 'typescript'", err.Error())
+}
+
+func TestRemoveAliasShouldRemoveAlias(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "javascript")
+	assert.Nil(t, err)
+	assert.Nil(t, tag.AddAlias(db, "js"))
+	assert.Nil(t, tag.AddAlias(db, "ecmascript"))
+
+	aliases, err := tag.FindAliases(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(aliases))
+	assert.Equal(t, "ecmascript", aliases[0].Name)
+
+	assert.Nil(t, tag.RemoveAlias(db, "JS"))
+	assert.NotNil(t, tag.RemoveAlias(db, "js"))
+
+	found, err := FindTagByName(db, "js")
+	assert.Nil(t, err)
+	assert.Nil(t, found)
+}
+
+func TestRenameTagToAliasShouldRemoveAlias(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "javascript")
+	assert.Nil(t, err)
+	assert.Nil(t, tag.AddAlias(db, "js"))
+
+	assert.Nil(t, tag.Rename(db, "js"))
+
+	aliases, err := tag.FindAliases(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(aliases))
+}
@@ -0,0 +1,209 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Kinds of changes an update can find
+const (
+	ChangeStarred            = "starred"
+	ChangeUnstarred          = "unstarred"
+	ChangeRenamed            = "renamed"
+	ChangeLanguage           = "language"
+	ChangeDescriptionRemoved = "description-removed"
+)
+
+// ChangeKinds lists the kinds of changes in the order to report them
+var ChangeKinds = []string{
+	ChangeStarred,
+	ChangeUnstarred,
+	ChangeRenamed,
+	ChangeLanguage,
+	ChangeDescriptionRemoved,
+}
+
+// UpdateRun records an update of your stars from a service and what it changed
+type UpdateRun struct {
+	gorm.Model
+	ServiceID uint
+	Created   int
+	Updated   int
+	Errors    int
+	// Complete is whether the update saved every star the service listed, so a star it didn't
+	// see has been unstarred
+	Complete bool
+	Changes  []StarChange `gorm:"-"`
+}
+
+// StarChange is a notable change to a star found by an update
+type StarChange struct {
+	ID          uint `gorm:"primary_key"`
+	UpdateRunID uint `gorm:"index"`
+	StarID      uint
+	Kind        string
+	FullName    string
+	OldValue    string `gorm:"type:text"`
+	NewValue    string
+}
+
+// String describes the change
+func (change *StarChange) String() string {
+	switch change.Kind {
+	case ChangeStarred:
+		return fmt.Sprintf("+ %s starred", change.FullName)
+	case ChangeUnstarred:
+		return fmt.Sprintf("- %s unstarred", change.FullName)
+	case ChangeRenamed:
+		return fmt.Sprintf("~ %s renamed from %s", change.FullName, change.OldValue)
+	case ChangeLanguage:
+		return fmt.Sprintf("~ %s changed language from %s to %s", change.FullName,
+			orNone(change.OldValue), orNone(change.NewValue))
+	default:
+		return fmt.Sprintf("~ %s removed its description", change.FullName)
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// StartUpdateRun records the start of an update from a service
+func StartUpdateRun(db *gorm.DB, service *Service) (*UpdateRun, error) {
+	run := &UpdateRun{ServiceID: service.ID}
+	return run, db.Create(run).Error
+}
+
+// CreateOrUpdateStar creates or updates a star like CreateOrUpdateStar, and records what changed
+func (run *UpdateRun) CreateOrUpdateStar(db *gorm.DB, star *Star, service *Service) (bool, error) {
+	var existing Star
+	found := !db.Where("remote_id = ? AND service_id = ?", star.RemoteID, service.ID).First(&existing).RecordNotFound()
+
+	created, err := CreateOrUpdateStar(db, star, service)
+	if err != nil {
+		return created, err
+	}
+
+	var changes []StarChange
+	if created {
+		run.Created++
+		changes = append(changes, StarChange{Kind: ChangeStarred})
+	} else {
+		run.Updated++
+		if found {
+			changes = diffStars(&existing, star)
+		}
+	}
+	return created, run.record(db, star, changes)
+}
+
+// diffStars finds the notable changes between a star's stored and updated versions
+func diffStars(old *Star, star *Star) []StarChange {
+	var changes []StarChange
+	oldName, newName := stringOrEmpty(old.FullName), stringOrEmpty(star.FullName)
+	if oldName != newName {
+		changes = append(changes, StarChange{Kind: ChangeRenamed, OldValue: oldName, NewValue: newName})
+	}
+
+	oldLanguage, newLanguage := stringOrEmpty(old.Language), stringOrEmpty(star.Language)
+	if !strings.EqualFold(oldLanguage, newLanguage) {
+		changes = append(changes, StarChange{Kind: ChangeLanguage, OldValue: oldLanguage, NewValue: newLanguage})
+	}
+
+	oldDescription := stringOrEmpty(old.Description)
+	if oldDescription != "" && stringOrEmpty(star.Description) == "" {
+		changes = append(changes, StarChange{Kind: ChangeDescriptionRemoved, OldValue: oldDescription})
+	}
+	return changes
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (run *UpdateRun) record(db *gorm.DB, star *Star, changes []StarChange) error {
+	for _, change := range changes {
+		change.UpdateRunID = run.ID
+		change.StarID = star.ID
+		change.FullName = stringOrEmpty(star.FullName)
+		if err := db.Create(&change).Error; err != nil {
+			return err
+		}
+		run.Changes = append(run.Changes, change)
+	}
+	return nil
+}
+
+// Finish records the stars that were unstarred since the previous successful update -- the ones
+// that update saw but this one didn't -- and saves the run's totals. Only record unstarred stars
+// for a successful, complete update, or every star it didn't get to would look unstarred
+func (run *UpdateRun) Finish(db *gorm.DB, previousSuccess time.Time, successful bool) error {
+	if successful && run.Complete && !previousSuccess.IsZero() {
+		var stars []Star
+		if err := db.Where("service_id = ? AND updated_at >= ? AND updated_at < ?",
+			run.ServiceID, previousSuccess, run.CreatedAt).Order("full_name").Find(&stars).Error; err != nil {
+			return err
+		}
+		for i := range stars {
+			if err := run.record(db, &stars[i], []StarChange{{Kind: ChangeUnstarred}}); err != nil {
+				return err
+			}
+		}
+	}
+	return db.Save(run).Error
+}
+
+// CountChanges counts the run's changes by kind
+func (run *UpdateRun) CountChanges() map[string]int {
+	counts := make(map[string]int)
+	for _, change := range run.Changes {
+		counts[change.Kind]++
+	}
+	return counts
+}
+
+// LoadChanges loads the run's changes
+func (run *UpdateRun) LoadChanges(db *gorm.DB) error {
+	return db.Where("update_run_id = ?", run.ID).Order("id").Find(&run.Changes).Error
+}
+
+// FindLastUpdateRun finds the most recent update, or nil if there hasn't been one
+func FindLastUpdateRun(db *gorm.DB) (*UpdateRun, error) {
+	var run UpdateRun
+	if db.Order("id desc").First(&run).RecordNotFound() {
+		return nil, db.Error
+	}
+	return &run, run.LoadChanges(db)
+}
+
+// FindUpdateRunsFrom finds the updates starting with the one with the specified ID, oldest first
+func FindUpdateRunsFrom(db *gorm.DB, ID uint) ([]UpdateRun, error) {
+	return findUpdateRuns(db, db.Where("id >= ?", ID))
+}
+
+// FindUpdateRunsSince finds the updates since a time, oldest first
+func FindUpdateRunsSince(db *gorm.DB, since time.Time) ([]UpdateRun, error) {
+	return findUpdateRuns(db, db.Where("created_at >= ?", since))
+}
+
+func findUpdateRuns(db *gorm.DB, scope *gorm.DB) ([]UpdateRun, error) {
+	var runs []UpdateRun
+	if err := scope.Order("id").Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if err := runs[i].LoadChanges(db); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateRunShouldRecordChanges(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	goLang, rust, description := "Go", "Rust", "A star"
+	names := []string{"a/kept", "a/renamed", "a/moved", "a/gone"}
+	first, err := StartUpdateRun(db, service)
+	assert.Nil(t, err)
+	for i := range names {
+		star := &Star{RemoteID: names[i], FullName: &names[i], Language: &goLang, Description: &description}
+		_, err = first.CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, first.Finish(db, time.Time{}, true))
+	assert.Equal(t, 4, first.Created)
+	assert.Equal(t, 4, first.CountChanges()[ChangeStarred])
+
+	time.Sleep(10 * time.Millisecond)
+	second, err := StartUpdateRun(db, service)
+	assert.Nil(t, err)
+	newName, newStar := "b/renamed", "a/new"
+	for _, star := range []*Star{
+		{RemoteID: "a/kept", FullName: &names[0], Language: &goLang, Description: &description},
+		{RemoteID: "a/renamed", FullName: &newName, Language: &goLang},
+		{RemoteID: "a/moved", FullName: &names[2], Language: &rust, Description: &description},
+		{RemoteID: "a/new", FullName: &newStar},
+	} {
+		_, err = second.CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+	}
+	second.Complete = true
+	assert.Nil(t, second.Finish(db, first.CreatedAt, true))
+	assert.Equal(t, 1, second.Created)
+	assert.Equal(t, 3, second.Updated)
+
+	last, err := FindLastUpdateRun(db)
+	assert.Nil(t, err)
+	assert.Equal(t, second.ID, last.ID)
+
+	var descriptions []string
+	for _, change := range last.Changes {
+		descriptions = append(descriptions, change.String())
+	}
+	assert.Equal(t, []string{
+		"~ b/renamed renamed from a/renamed",
+		"~ b/renamed removed its description",
+		"~ a/moved changed language from Go to Rust",
+		"+ a/new starred",
+		"- a/gone unstarred",
+	}, descriptions)
+
+	runs, err := FindUpdateRunsFrom(db, first.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(runs))
+
+	runs, err = FindUpdateRunsSince(db, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(runs))
+}
+
+func TestUpdateRunShouldNotRecordUnstarredWhenUnsuccessful(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	name := "a/star"
+	_, err = CreateOrUpdateStar(db, &Star{RemoteID: name, FullName: &name}, service)
+	assert.Nil(t, err)
+
+	run, err := StartUpdateRun(db, service)
+	assert.Nil(t, err)
+	assert.Nil(t, run.Finish(db, time.Now().Add(-time.Hour), false))
+	assert.Equal(t, 0, len(run.Changes))
+}
+
+func TestUpdateRunShouldNotRecordUnstarredWhenIncomplete(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	names := []string{"a/reached", "a/missed"}
+	for i := range names {
+		_, err = CreateOrUpdateStar(db, &Star{RemoteID: names[i], FullName: &names[i]}, service)
+		assert.Nil(t, err)
+	}
+	previousSuccess := time.Now().Add(-time.Hour)
+
+	time.Sleep(10 * time.Millisecond)
+	run, err := StartUpdateRun(db, service)
+	assert.Nil(t, err)
+	_, err = run.CreateOrUpdateStar(db, &Star{RemoteID: names[0], FullName: &names[0]}, service)
+	assert.Nil(t, err)
+	assert.Nil(t, run.Finish(db, previousSuccess, true))
+	assert.Equal(t, 0, run.CountChanges()[ChangeUnstarred])
+
+	last, err := FindLastUpdateRun(db)
+	assert.Nil(t, err)
+	assert.False(t, last.Complete)
+}
@@ -1,20 +1,56 @@
 package model
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/jinzhu/gorm"
 	// Use the sqlite dialect
 	_ "github.com/jinzhu/gorm/dialects/sqlite"
 )
 
-// InitDB initializes the database at the specified path
-func InitDB(filepath string, verbose bool) (*gorm.DB, error) {
-	db, err := gorm.Open("sqlite3", filepath)
+// dialects are the database dialects limo was built with. SQLite is always available;
+// build with -tags postgres for PostgreSQL
+var dialects = map[string]bool{
+	"sqlite3": true,
+}
+
+// ParseDatabaseURL returns the dialect and data source for a database URL. A URL starting with
+// postgres:// or postgresql:// is a PostgreSQL database, and anything else is the path to a SQLite
+// database, optionally starting with sqlite://
+func ParseDatabaseURL(url string) (string, string) {
+	if strings.HasPrefix(url, "postgres://") || strings.HasPrefix(url, "postgresql://") {
+		return "postgres", url
+	}
+	return "sqlite3", strings.TrimPrefix(url, "sqlite://")
+}
+
+// InitDB opens the database at the specified URL or path and applies any pending migrations
+func InitDB(url string, verbose bool) (*gorm.DB, error) {
+	db, err := OpenDB(url, verbose)
 	if err != nil {
 		return nil, err
 	}
 
-	db.LogMode(verbose)
-	db.AutoMigrate(&Service{}, &Star{}, &Tag{})
+	if _, _, err := Migrate(db, url); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
 
+// OpenDB opens the database at the specified URL or path without migrating it
+func OpenDB(url string, verbose bool) (*gorm.DB, error) {
+	dialect, source := ParseDatabaseURL(url)
+	if !dialects[dialect] {
+		return nil, fmt.Errorf("this build of limo doesn't support %s -- build it with -tags %s", dialect, dialect)
+	}
+
+	db, err := gorm.Open(dialect, source)
+	if err != nil {
+		return nil, err
+	}
+
+	db.LogMode(verbose)
 	return db, nil
 }
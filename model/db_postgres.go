@@ -0,0 +1,13 @@
+//go:build postgres
+// +build postgres
+
+package model
+
+import (
+	// Use the postgres dialect
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+)
+
+func init() {
+	dialects["postgres"] = true
+}
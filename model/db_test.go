@@ -6,11 +6,15 @@ import (
 	"testing"
 
 	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
 )
 
 var dbPath = "./test.db"
 var db *gorm.DB
 
+// testDatabaseURL runs the tests against another database, like PostgreSQL, instead of a SQLite file
+var testDatabaseURL = os.Getenv("LIMO_TEST_DATABASE_URL")
+
 func TestMain(m *testing.M) {
 	rmDB()
 	mkDB()
@@ -20,8 +24,13 @@ func TestMain(m *testing.M) {
 }
 
 func mkDB() {
+	url := dbPath
+	if testDatabaseURL != "" {
+		url = testDatabaseURL
+	}
+
 	var err error
-	db, err = InitDB(dbPath, false)
+	db, err = InitDB(url, false)
 	if err != nil {
 		panic(err)
 	}
@@ -33,6 +42,15 @@ func clearDB() {
 		"stars",
 		"tags",
 		"star_tags",
+		"tag_aliases",
+		"list_mappings",
+		"saved_searches",
+		"star_snapshots",
+		"update_runs",
+		"star_changes",
+		"tag_snapshots",
+		"operations",
+		"operation_steps",
 	} {
 		db.Exec(fmt.Sprintf("delete from %s", table))
 	}
@@ -43,3 +61,18 @@ func rmDB() {
 		panic(err)
 	}
 }
+
+func TestParseDatabaseURLShouldFindTheDialect(t *testing.T) {
+	for _, test := range []struct {
+		url, dialect, source string
+	}{
+		{"/home/me/.config/limo/limo.db", "sqlite3", "/home/me/.config/limo/limo.db"},
+		{"sqlite:///tmp/limo.db", "sqlite3", "/tmp/limo.db"},
+		{"postgres://limo@localhost/limo", "postgres", "postgres://limo@localhost/limo"},
+		{"postgresql://limo@localhost/limo", "postgres", "postgresql://limo@localhost/limo"},
+	} {
+		dialect, source := ParseDatabaseURL(test.url)
+		assert.Equal(t, test.dialect, dialect, test.url)
+		assert.Equal(t, test.source, source, test.url)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/go-github/github"
 	"github.com/skratchdot/open-golang/open"
+	"github.com/xanzy/go-gitlab"
 )
 
 const defaultWho = "somebody"
@@ -27,6 +28,33 @@ var eventTypes = map[string]string{
 	"WatchEvent":        "starred",
 }
 
+var gitlabActions = map[string]string{
+	"accepted":     "accepted",
+	"approved":     "approved",
+	"closed":       "closed",
+	"commented on": "commented on",
+	"created":      "created",
+	"deleted":      "deleted",
+	"destroyed":    "deleted",
+	"joined":       "joined",
+	"left":         "left",
+	"merged":       "merged",
+	"opened":       "opened",
+	"pushed new":   "pushed to",
+	"pushed to":    "pushed to",
+	"reopened":     "reopened",
+	"updated":      "updated",
+}
+
+var gitlabTargetTypes = map[string]string{
+	"Commit":         "a commit on",
+	"Issue":          "an issue on",
+	"MergeRequest":   "a merge request on",
+	"Milestone":      "a milestone on",
+	"Snippet":        "a snippet on",
+	"WikiPage::Meta": "a wiki page on",
+}
+
 // Event is a git-hosting service event
 type Event struct {
 	Who   string
@@ -79,6 +107,51 @@ func NewEventFromGithub(event *github.Event) *Event {
 	}
 }
 
+// NewEventFromGitlab creates an Event from a GitLab event and the project it happened on,
+// which can be nil if it's unknown
+func NewEventFromGitlab(event *gitlab.ContributionEvent, project *gitlab.Project) *Event {
+	who := defaultWho
+	if event.Author.Username != "" {
+		who = event.Author.Username
+	} else if event.AuthorUsername != "" {
+		who = event.AuthorUsername
+	}
+
+	what := defaultWhat
+	if action, ok := gitlabActions[event.ActionName]; ok {
+		what = action
+
+		// Comments are on notes, so describe what the note is on
+		targetType := event.TargetType
+		if event.Note != nil && event.Note.NoteableType != "" {
+			targetType = event.Note.NoteableType
+		}
+		if target, ok := gitlabTargetTypes[targetType]; ok {
+			what = fmt.Sprintf("%s %s", action, target)
+		}
+	}
+
+	which := defaultWhich
+	url := ""
+	if project != nil && project.PathWithNamespace != "" {
+		which = project.PathWithNamespace
+		url = project.WebURL
+	}
+
+	when := time.Now()
+	if event.CreatedAt != nil {
+		when = *event.CreatedAt
+	}
+
+	return &Event{
+		Who:   who,
+		What:  what,
+		Which: which,
+		URL:   url,
+		When:  when,
+	}
+}
+
 // OpenInBrowser opens the event in the browser
 func (event *Event) OpenInBrowser() error {
 	if event.URL == "" {
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestNewEventFromGitlabShouldMapActions(t *testing.T) {
+	event := NewEventFromGitlab(&gitlab.ContributionEvent{ActionName: "pushed new", AuthorUsername: "hoop33"}, nil)
+	assert.Equal(t, "hoop33", event.Who)
+	assert.Equal(t, "pushed to", event.What)
+	assert.Equal(t, defaultWhich, event.Which)
+	assert.Equal(t, "", event.URL)
+
+	event = NewEventFromGitlab(&gitlab.ContributionEvent{ActionName: "closed", TargetType: "Issue"}, nil)
+	assert.Equal(t, defaultWho, event.Who)
+	assert.Equal(t, "closed an issue on", event.What)
+
+	event = NewEventFromGitlab(&gitlab.ContributionEvent{ActionName: "frobbed"}, nil)
+	assert.Equal(t, defaultWhat, event.What)
+}
+
+func TestNewEventFromGitlabShouldDescribeWhatCommentsAreOn(t *testing.T) {
+	event := NewEventFromGitlab(&gitlab.ContributionEvent{
+		ActionName: "commented on",
+		TargetType: "DiffNote",
+		Note:       &gitlab.Note{NoteableType: "MergeRequest"},
+	}, &gitlab.Project{PathWithNamespace: "hoop33/limo", WebURL: "https://gitlab.com/hoop33/limo"})
+	assert.Equal(t, "commented on a merge request on", event.What)
+	assert.Equal(t, "hoop33/limo", event.Which)
+	assert.Equal(t, "https://gitlab.com/hoop33/limo", event.URL)
+}
@@ -1,26 +1,107 @@
 package model
 
 import (
+	"os"
+	"strings"
+
 	"github.com/blevesearch/bleve"
 	"github.com/blevesearch/bleve/analysis/analyzers/keyword_analyzer"
 	"github.com/blevesearch/bleve/analysis/analyzers/simple_analyzer"
 	"github.com/blevesearch/bleve/analysis/language/en"
+	"github.com/jinzhu/gorm"
 )
 
+// indexMappingVersion is the version of the mapping buildIndexMapping builds. Bleve keeps the mapping an
+// index was created with, so bump this when the mapping changes, and existing indexes will be rebuilt
+const indexMappingVersion = "2"
+
+// indexMappingVersionKey is where an index stores its mapping version. Indexes from before
+// versioning don't have one
+var indexMappingVersionKey = []byte("mappingVersion")
+
 // InitIndex initializes the search index at the specified path
 func InitIndex(filepath string) (bleve.Index, error) {
 	index, err := bleve.Open(filepath)
 
 	// Doesn't yet exist (or error opening) so create a new one
 	if err != nil {
-		index, err = bleve.New(filepath, buildIndexMapping())
-		if err != nil {
-			return nil, err
+		return newIndex(filepath)
+	}
+	return index, nil
+}
+
+// IndexIsCurrent returns whether an index uses the current mapping
+func IndexIsCurrent(index bleve.Index) (bool, error) {
+	version, err := index.GetInternal(indexMappingVersionKey)
+	if err != nil {
+		return false, err
+	}
+	return string(version) == indexMappingVersion, nil
+}
+
+// RebuildIndex replaces the index at the specified path with a new one using the current mapping,
+// and indexes all the stars in it. It closes the old index
+func RebuildIndex(index bleve.Index, filepath string, db *gorm.DB) (bleve.Index, error) {
+	if err := index.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(filepath); err != nil {
+		return nil, err
+	}
+
+	index, err := newIndex(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	stars, err := FindStars(db, "")
+	if err != nil {
+		return index, err
+	}
+	for i := range stars {
+		if err := stars[i].Index(index, db); err != nil {
+			return index, err
 		}
 	}
 	return index, nil
 }
 
+func newIndex(filepath string) (bleve.Index, error) {
+	index, err := bleve.New(filepath, buildIndexMapping())
+	if err != nil {
+		return nil, err
+	}
+	if err := index.SetInternal(indexMappingVersionKey, []byte(indexMappingVersion)); err != nil {
+		_ = index.Close()
+		return nil, err
+	}
+	return index, nil
+}
+
+// OpenIndexReadOnly opens the search index at the specified path for searching only, so other
+// processes can read it at the same time. If the index doesn't exist yet, it initializes it
+func OpenIndexReadOnly(filepath string) (bleve.Index, error) {
+	index, err := bleve.OpenUsing(filepath, map[string]interface{}{
+		"read_only": true,
+	})
+	if err != nil {
+		return InitIndex(filepath)
+	}
+	return index, nil
+}
+
+// NewIncrementalQuery creates a query for search-as-you-type, treating the last word as a prefix
+func NewIncrementalQuery(s string) bleve.Query {
+	terms := strings.Fields(s)
+	if len(terms) == 0 {
+		return bleve.NewMatchQuery(s)
+	}
+	return bleve.NewDisjunctionQuery([]bleve.Query{
+		bleve.NewMatchQuery(s),
+		bleve.NewPrefixQuery(strings.ToLower(terms[len(terms)-1])),
+	})
+}
+
 func buildIndexMapping() *bleve.IndexMapping {
 	simpleTextFieldMapping := bleve.NewTextFieldMapping()
 	simpleTextFieldMapping.Analyzer = simple_analyzer.Name
@@ -37,6 +118,10 @@ func buildIndexMapping() *bleve.IndexMapping {
 	starMapping.AddFieldMappingsAt("Description", englishTextFieldMapping)
 	starMapping.AddFieldMappingsAt("Language", keywordFieldMapping)
 	starMapping.AddFieldMappingsAt("Tags.Name", keywordFieldMapping)
+	starMapping.AddFieldMappingsAt("Topics", keywordFieldMapping)
+	starMapping.AddFieldMappingsAt("License", keywordFieldMapping)
+	starMapping.AddFieldMappingsAt("Owner", keywordFieldMapping)
+	starMapping.AddFieldMappingsAt("Note", englishTextFieldMapping)
 
 	indexMapping := bleve.NewIndexMapping()
 	indexMapping.AddDocumentMapping("Star", starMapping)
@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/blevesearch/bleve"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,3 +25,74 @@ func rmIndex() {
 		panic(err)
 	}
 }
+
+func TestIndexShouldIndexTopicsAsKeywords(t *testing.T) {
+	rmIndex()
+	defer rmIndex()
+	clearDB()
+
+	index, err := InitIndex(indexPath)
+	assert.Nil(t, err)
+	defer index.Close()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "1", Topics: Topics{"hall-of-fame"}}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+	assert.Nil(t, star.Index(index, db))
+
+	query := bleve.NewTermQuery("hall-of-fame")
+	query.SetField("Topics")
+	results, err := index.Search(bleve.NewSearchRequest(query))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), results.Total)
+}
+
+func TestInitIndexShouldCreateCurrentIndex(t *testing.T) {
+	rmIndex()
+	defer rmIndex()
+
+	index, err := InitIndex(indexPath)
+	assert.Nil(t, err)
+	defer index.Close()
+
+	current, err := IndexIsCurrent(index)
+	assert.Nil(t, err)
+	assert.True(t, current)
+}
+
+func TestRebuildIndexShouldUpgradeOldIndexAndIndexStars(t *testing.T) {
+	rmIndex()
+	defer rmIndex()
+	clearDB()
+
+	// An index from before mappings were versioned
+	index, err := bleve.New(indexPath, bleve.NewIndexMapping())
+	assert.Nil(t, err)
+
+	current, err := IndexIsCurrent(index)
+	assert.Nil(t, err)
+	assert.False(t, current)
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+	fullName := "hoop33/limo"
+	_, err = CreateOrUpdateStar(db, &Star{RemoteID: "1", FullName: &fullName, Topics: Topics{"hall-of-fame"}}, service)
+	assert.Nil(t, err)
+
+	index, err = RebuildIndex(index, indexPath, db)
+	assert.Nil(t, err)
+	defer index.Close()
+
+	current, err = IndexIsCurrent(index)
+	assert.Nil(t, err)
+	assert.True(t, current)
+
+	query := bleve.NewTermQuery("hall-of-fame")
+	query.SetField("Topics")
+	results, err := index.Search(bleve.NewSearchRequest(query))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), results.Total)
+}
@@ -0,0 +1,198 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// List represents a list of stars on a remote service, like a GitHub star list
+type List struct {
+	RemoteID      string
+	Name          string
+	StarRemoteIDs []string
+}
+
+// ListMapping maps a tag to a list on a remote service
+type ListMapping struct {
+	gorm.Model
+	TagID     uint
+	ServiceID uint
+	RemoteID  string
+}
+
+// ListMove describes a change to the lists a star belongs to
+type ListMove struct {
+	Star *Star
+	From []string
+	To   []string
+}
+
+// ListPlan describes the changes needed to push tags to remote lists
+type ListPlan struct {
+	Creates []Tag
+	Adopts  []ListMapping
+	Moves   []ListMove
+}
+
+// FindListMappingByTag finds the mapping for a tag on a service
+func FindListMappingByTag(db *gorm.DB, tag *Tag, service *Service) (*ListMapping, error) {
+	var mapping ListMapping
+	if db.Where("tag_id = ? AND service_id = ?", tag.ID, service.ID).First(&mapping).RecordNotFound() {
+		return nil, db.Error
+	}
+	return &mapping, db.Error
+}
+
+// FindListMappingByRemoteID finds the mapping for a remote list on a service
+func FindListMappingByRemoteID(db *gorm.DB, remoteID string, service *Service) (*ListMapping, error) {
+	var mapping ListMapping
+	if db.Where("remote_id = ? AND service_id = ?", remoteID, service.ID).First(&mapping).RecordNotFound() {
+		return nil, db.Error
+	}
+	return &mapping, db.Error
+}
+
+// FindListMappingsByService finds all the mappings for a service
+func FindListMappingsByService(db *gorm.DB, service *Service) ([]ListMapping, error) {
+	var mappings []ListMapping
+	db.Where("service_id = ?", service.ID).Find(&mappings)
+	return mappings, db.Error
+}
+
+// CreateOrUpdateListMapping maps a tag to a remote list, replacing any existing mapping for the tag
+func CreateOrUpdateListMapping(db *gorm.DB, tag *Tag, service *Service, remoteID string) (*ListMapping, error) {
+	mapping, err := FindListMappingByTag(db, tag, service)
+	if err != nil {
+		return nil, err
+	}
+	if mapping == nil {
+		mapping = &ListMapping{
+			TagID:     tag.ID,
+			ServiceID: service.ID,
+		}
+	}
+	mapping.RemoteID = remoteID
+	return mapping, db.Save(mapping).Error
+}
+
+// PlanListPush determines which lists to create and which stars to move so that the
+// remote lists match the local tags. Lists that aren't mapped to a tag are left alone.
+// When additive is true, stars are never removed from lists.
+func PlanListPush(db *gorm.DB, service *Service, lists []*List, additive bool) (*ListPlan, error) {
+	plan := &ListPlan{}
+
+	remoteByID := make(map[string]*List)
+	remoteByName := make(map[string]*List)
+	for _, list := range lists {
+		remoteByID[list.RemoteID] = list
+		remoteByName[strings.ToLower(list.Name)] = list
+	}
+
+	tags, err := FindTags(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map each tag to a remote list, adopting lists with the same name
+	listIDsByTag := make(map[uint]string)
+	managed := make(map[string]bool)
+	used := make(map[string]bool)
+	for _, tag := range tags {
+		mapping, err := FindListMappingByTag(db, &tag, service)
+		if err != nil {
+			return nil, err
+		}
+		if mapping != nil && remoteByID[mapping.RemoteID] != nil {
+			listIDsByTag[tag.ID] = mapping.RemoteID
+		} else if list, ok := remoteByName[strings.ToLower(tag.Name)]; ok {
+			listIDsByTag[tag.ID] = list.RemoteID
+			plan.Adopts = append(plan.Adopts, ListMapping{
+				TagID:     tag.ID,
+				ServiceID: service.ID,
+				RemoteID:  list.RemoteID,
+			})
+		} else {
+			listIDsByTag[tag.ID] = PendingListID(&tag)
+		}
+		managed[listIDsByTag[tag.ID]] = true
+	}
+
+	// Find the lists each star currently belongs to
+	current := make(map[string][]string)
+	for _, list := range lists {
+		for _, remoteID := range list.StarRemoteIDs {
+			current[remoteID] = append(current[remoteID], list.RemoteID)
+		}
+	}
+
+	var stars []Star
+	db.Where("service_id = ?", service.ID).Order("full_name").Find(&stars)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	for i := range stars {
+		star := &stars[i]
+		if err := star.LoadTags(db); err != nil {
+			return nil, err
+		}
+
+		// Keep lists limo doesn't manage, and add the lists for the star's tags
+		var to []string
+		has := make(map[string]bool)
+		for _, listID := range current[star.RemoteID] {
+			if additive || !managed[listID] {
+				to = append(to, listID)
+				has[listID] = true
+			}
+		}
+		for _, tag := range star.Tags {
+			if listID, ok := listIDsByTag[tag.ID]; ok && !has[listID] {
+				to = append(to, listID)
+				has[listID] = true
+				used[listID] = true
+			}
+		}
+
+		from := current[star.RemoteID]
+		if !sameListIDs(from, to) {
+			plan.Moves = append(plan.Moves, ListMove{
+				Star: star,
+				From: from,
+				To:   to,
+			})
+		}
+	}
+
+	// Create lists only for tags that have stars on this service
+	for _, tag := range tags {
+		if listIDsByTag[tag.ID] == PendingListID(&tag) && used[PendingListID(&tag)] {
+			plan.Creates = append(plan.Creates, tag)
+		}
+	}
+	return plan, nil
+}
+
+// PendingListID returns the placeholder ID used in a plan for a list that doesn't yet exist
+func PendingListID(tag *Tag) string {
+	return fmt.Sprintf("pending:%d", tag.ID)
+}
+
+func sameListIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sa := append([]string{}, a...)
+	sb := append([]string{}, b...)
+	sort.Strings(sa)
+	sort.Strings(sb)
+	for i := range sa {
+		if sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateOrUpdateListMappingShouldReplaceRemoteID(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+
+	tag, _, err := FindOrCreateTagByName(db, "celtics")
+	assert.Nil(t, err)
+
+	_, err = CreateOrUpdateListMapping(db, tag, service, "list-1")
+	assert.Nil(t, err)
+	_, err = CreateOrUpdateListMapping(db, tag, service, "list-2")
+	assert.Nil(t, err)
+
+	mappings, err := FindListMappingsByService(db, service)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(mappings))
+	assert.Equal(t, "list-2", mappings[0].RemoteID)
+
+	mapping, err := FindListMappingByRemoteID(db, "list-2", service)
+	assert.Nil(t, err)
+	assert.Equal(t, tag.ID, mapping.TagID)
+}
+
+func TestFindListMappingByTagShouldReturnNilWhenNotMapped(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+
+	tag, _, err := FindOrCreateTagByName(db, "celtics")
+	assert.Nil(t, err)
+
+	mapping, err := FindListMappingByTag(db, tag, service)
+	assert.Nil(t, err)
+	assert.Nil(t, mapping)
+}
+
+func TestPlanListPushShouldCreateListsOnlyForTagsInUse(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "33"}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	celtics, _, err := FindOrCreateTagByName(db, "celtics")
+	assert.Nil(t, err)
+	_, _, err = FindOrCreateTagByName(db, "lakers")
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTag(db, celtics))
+
+	plan, err := PlanListPush(db, service, nil, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(plan.Creates))
+	assert.Equal(t, "celtics", plan.Creates[0].Name)
+	assert.Equal(t, 1, len(plan.Moves))
+	assert.Equal(t, []string{PendingListID(celtics)}, plan.Moves[0].To)
+}
+
+func TestPlanListPushShouldAdoptListsWithSameName(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "33"}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	celtics, _, err := FindOrCreateTagByName(db, "celtics")
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTag(db, celtics))
+
+	plan, err := PlanListPush(db, service, []*List{
+		{RemoteID: "L1", Name: "Celtics", StarRemoteIDs: []string{"33"}},
+	}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(plan.Creates))
+	assert.Equal(t, 1, len(plan.Adopts))
+	assert.Equal(t, "L1", plan.Adopts[0].RemoteID)
+	assert.Equal(t, 0, len(plan.Moves))
+}
+
+func TestPlanListPushShouldRemoveUntaggedStarsFromManagedLists(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "33"}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	celtics, _, err := FindOrCreateTagByName(db, "celtics")
+	assert.Nil(t, err)
+	_, err = CreateOrUpdateListMapping(db, celtics, service, "L1")
+	assert.Nil(t, err)
+
+	lists := []*List{
+		{RemoteID: "L1", Name: "celtics", StarRemoteIDs: []string{"33"}},
+		{RemoteID: "L2", Name: "mine", StarRemoteIDs: []string{"33"}},
+	}
+
+	plan, err := PlanListPush(db, service, lists, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(plan.Moves))
+	assert.Equal(t, []string{"L2"}, plan.Moves[0].To)
+
+	plan, err = PlanListPush(db, service, lists, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(plan.Moves))
+}
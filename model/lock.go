@@ -0,0 +1,146 @@
+package model
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockRetryInterval is how often to try again for a lock while waiting for it
+const lockRetryInterval = 100 * time.Millisecond
+
+// Lock is an advisory lock on the database and search index, so limo processes don't
+// conflict -- many can hold a shared lock to read, or one an exclusive lock to write.
+// The lock file holds the ID and command of the process that last took the lock, and whether
+// it keeps the lock until it's stopped
+type Lock struct {
+	Exclusive    bool
+	path         string
+	file         *os.File
+	held         bool
+	untilStopped bool
+}
+
+// untilStoppedLine marks a lock file whose holder keeps the lock until it's stopped
+const untilStoppedLine = "until stopped"
+
+// LockedError reports that another process holds the lock
+type LockedError struct {
+	PID          int
+	Command      string
+	UntilStopped bool
+}
+
+// Error describes the process holding the lock
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return "the database is in use by another limo process -- use --wait to wait for it"
+	}
+	if e.UntilStopped {
+		return fmt.Sprintf("the database is in use by process %d (%s), which keeps it until you stop it", e.PID, e.Command)
+	}
+	return fmt.Sprintf("the database is in use by process %d (%s) -- use --wait to wait for it", e.PID, e.Command)
+}
+
+// AcquireLock takes the lock at path, waiting up to wait for another process to release it
+func AcquireLock(path string, exclusive bool, wait time.Duration) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{
+		path: path,
+		file: file,
+	}
+	if err := lock.take(exclusive, wait); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Relock changes the lock to exclusive or shared, waiting up to wait for other processes.
+// Like flock, it doesn't change the lock atomically, so another process can take it in between
+func (lock *Lock) Relock(exclusive bool, wait time.Duration) error {
+	return lock.take(exclusive, wait)
+}
+
+// KeepUntilStopped records that the process keeps the lock until it's stopped, so other processes
+// don't tell you to wait for it
+func (lock *Lock) KeepUntilStopped() error {
+	lock.untilStopped = true
+	return lock.writeHolder()
+}
+
+func (lock *Lock) take(exclusive bool, wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+	for {
+		locked, err := flock(lock.file, exclusive)
+		if err != nil {
+			return err
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			holder := readLockHolder(lock.path)
+			if lock.held {
+				// Trying to change a lock can drop it, so take back the one we had
+				if _, err := flock(lock.file, lock.Exclusive); err != nil {
+					return err
+				}
+			}
+			return holder
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	lock.Exclusive = exclusive
+	lock.held = true
+	return lock.writeHolder()
+}
+
+// Release releases the lock. Exiting releases it, too
+func (lock *Lock) Release() error {
+	if err := funlock(lock.file); err != nil {
+		return err
+	}
+	return lock.file.Close()
+}
+
+func (lock *Lock) writeHolder() error {
+	if err := lock.file.Truncate(0); err != nil {
+		return err
+	}
+	holder := fmt.Sprintf("%d\n%s\n", os.Getpid(), strings.Join(os.Args, " "))
+	if lock.untilStopped {
+		holder += untilStoppedLine + "\n"
+	}
+	_, err := lock.file.WriteAt([]byte(holder), 0)
+	return err
+}
+
+func readLockHolder(path string) *LockedError {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return &LockedError{}
+	}
+	lines := strings.SplitN(string(data), "\n", 4)
+	pid, err := strconv.Atoi(lines[0])
+	if err != nil || len(lines) < 2 {
+		return &LockedError{}
+	}
+	return &LockedError{
+		PID:          pid,
+		Command:      lines[1],
+		UntilStopped: len(lines) > 2 && lines[2] == untilStoppedLine,
+	}
+}
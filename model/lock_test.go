@@ -0,0 +1,124 @@
+//go:build !windows
+// +build !windows
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireLockShouldShareReadLocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "limo.lock")
+	first, err := AcquireLock(path, false, 0)
+	assert.Nil(t, err)
+	second, err := AcquireLock(path, false, 0)
+	assert.Nil(t, err)
+
+	_, err = AcquireLock(path, true, 0)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, first.Release())
+	assert.Nil(t, second.Release())
+
+	exclusive, err := AcquireLock(path, true, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, exclusive.Release())
+}
+
+func TestAcquireLockShouldNameTheHolder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "limo.lock")
+	held, err := AcquireLock(path, true, 0)
+	assert.Nil(t, err)
+	defer held.Release()
+
+	start := time.Now()
+	_, err = AcquireLock(path, false, 300*time.Millisecond)
+	assert.True(t, time.Since(start) >= 300*time.Millisecond)
+
+	locked, ok := err.(*LockedError)
+	assert.True(t, ok)
+	assert.Equal(t, os.Getpid(), locked.PID)
+	assert.Contains(t, locked.Error(), "in use by process")
+}
+
+func TestAcquireLockShouldWaitForRelease(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "limo.lock")
+	held, err := AcquireLock(path, true, 0)
+	assert.Nil(t, err)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		held.Release()
+	}()
+
+	lock, err := AcquireLock(path, true, 5*time.Second)
+	assert.Nil(t, err)
+	assert.Nil(t, lock.Release())
+}
+
+func TestRelockShouldWaitForOtherReadersBeforeWriting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "limo.lock")
+	first, err := AcquireLock(path, false, 0)
+	assert.Nil(t, err)
+	second, err := AcquireLock(path, false, 0)
+	assert.Nil(t, err)
+
+	assert.NotNil(t, first.Relock(true, 0))
+	assert.False(t, first.Exclusive)
+
+	// The failed attempt keeps the shared lock
+	_, err = AcquireLock(path, true, 0)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, second.Release())
+	assert.Nil(t, first.Relock(true, 0))
+	assert.True(t, first.Exclusive)
+	_, err = AcquireLock(path, false, 0)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, first.Relock(false, 0))
+	third, err := AcquireLock(path, false, 0)
+	assert.Nil(t, err)
+	assert.Nil(t, third.Release())
+	assert.Nil(t, first.Release())
+}
+
+func TestAcquireLockShouldSayWhenTheHolderKeepsItUntilStopped(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "limo.lock")
+	held, err := AcquireLock(path, true, 0)
+	assert.Nil(t, err)
+	defer held.Release()
+	assert.Nil(t, held.KeepUntilStopped())
+
+	_, err = AcquireLock(path, false, 0)
+	locked, ok := err.(*LockedError)
+	assert.True(t, ok)
+	assert.True(t, locked.UntilStopped)
+	assert.Contains(t, locked.Error(), "until you stop it")
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package model
+
+import (
+	"os"
+	"syscall"
+)
+
+// flock takes a shared or exclusive lock on a file without blocking, and returns whether it got it
+func flock(file *os.File, exclusive bool) (bool, error) {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	err := syscall.Flock(int(file.Fd()), how|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func funlock(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
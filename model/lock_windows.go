@@ -0,0 +1,61 @@
+//go:build windows
+// +build windows
+
+package model
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32         = windows.NewLazySystemDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x00000001
+	lockfileExclusiveLock   = 0x00000002
+
+	errorLockViolation syscall.Errno = 33
+	errorNotLocked     syscall.Errno = 158
+)
+
+// lockedRange is the byte range to lock, far past the end of the file, since Windows locks
+// block reading and writing what they cover, and the lock file holds who has the lock
+func lockedRange() *windows.Overlapped {
+	return &windows.Overlapped{Offset: 0xffffffff, OffsetHigh: 0x7fffffff}
+}
+
+// flock takes a shared or exclusive lock on a file without blocking, and returns whether it got it.
+// Windows can't change a lock in place, so it drops any lock the file already has first
+func flock(file *os.File, exclusive bool) (bool, error) {
+	if err := funlock(file); err != nil && err != errorNotLocked {
+		return false, err
+	}
+
+	flags := uintptr(lockfileFailImmediately)
+	if exclusive {
+		flags |= lockfileExclusiveLock
+	}
+	r, _, err := procLockFileEx.Call(file.Fd(), flags, 0, 1, 0, uintptr(unsafe.Pointer(lockedRange())))
+	if r != 0 {
+		return true, nil
+	}
+	if err == errorLockViolation {
+		return false, nil
+	}
+	return false, err
+}
+
+func funlock(file *os.File) error {
+	r, _, err := procUnlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(lockedRange())))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
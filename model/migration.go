@@ -0,0 +1,539 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// SchemaMigration records a migration applied to the database
+type SchemaMigration struct {
+	Version   int `gorm:"primary_key;auto_increment:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migration changes the database schema or data from one version to the next
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(db *gorm.DB) error
+}
+
+// MigrationStatus is a migration and when it was applied, or nil if it's pending
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// migrations are the migrations in the order to apply them. Add new migrations to the end,
+// and never change one that's been released, because databases have already applied it. A migration
+// uses its own SQL rather than the model, so later changes to the model don't change what it does
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create tables",
+		Up: func(db *gorm.DB) error {
+			for _, table := range []table{
+				{name: "services", columns: withModel(
+					column{"name", "string"},
+					column{"last_success", "time"},
+				)},
+				{name: "stars", columns: withModel(
+					column{"remote_id", "string"},
+					column{"name", "string"},
+					column{"full_name", "string"},
+					column{"description", "text"},
+					column{"homepage", "string"},
+					column{"url", "string"},
+					column{"language", "string"},
+					column{"stargazers", "integer"},
+					column{"starred_at", "time"},
+					column{"service_id", "integer"},
+					column{"local_path", "string"},
+					column{"topics", "text"},
+					column{"license", "string"},
+					column{"forks", "integer"},
+					column{"open_issues", "integer"},
+					column{"archived", "bool"},
+					column{"fork", "bool"},
+					column{"default_branch", "string"},
+					column{"pushed_at", "time"},
+					column{"remote_created_at", "time"},
+					column{"owner", "string"},
+					column{"note", "text"},
+					column{"rating", "integer"},
+				)},
+				{name: "tags", columns: withModel(
+					column{"name", "string"},
+					column{"parent_id", "integer"},
+				)},
+				{name: "star_tags", primaryKey: []string{"star_id", "tag_id"}, columns: []column{
+					{"star_id", "integer"},
+					{"tag_id", "integer"},
+					{"source", "string"},
+				}},
+				{name: "tag_aliases", columns: withModel(
+					column{"name", "string"},
+					column{"tag_id", "integer"},
+				)},
+				{name: "list_mappings", columns: withModel(
+					column{"tag_id", "integer"},
+					column{"service_id", "integer"},
+					column{"remote_id", "string"},
+				)},
+				{name: "saved_searches", columns: withModel(
+					column{"name", "string"},
+					column{"query", "text"},
+				)},
+				{name: "star_snapshots", index: "star_id", columns: []column{
+					{"id", "id"},
+					{"star_id", "integer"},
+					{"date", "time"},
+					{"stargazers", "integer"},
+					{"forks", "integer"},
+					{"open_issues", "integer"},
+				}},
+				{name: "update_runs", columns: withModel(
+					column{"service_id", "integer"},
+					column{"created", "integer"},
+					column{"updated", "integer"},
+					column{"errors", "integer"},
+				)},
+				{name: "star_changes", index: "update_run_id", columns: []column{
+					{"id", "id"},
+					{"update_run_id", "integer"},
+					{"star_id", "integer"},
+					{"kind", "string"},
+					{"full_name", "string"},
+					{"old_value", "text"},
+					{"new_value", "string"},
+				}},
+				{name: "tag_snapshots", index: "tag_id", columns: []column{
+					{"id", "id"},
+					{"tag_id", "integer"},
+					{"star_id", "integer"},
+					{"source", "string"},
+					{"alias", "string"},
+				}},
+				{name: "operations", columns: withModel(
+					column{"command", "string"},
+					column{"undone_at", "time"},
+				)},
+				{name: "operation_steps", index: "operation_id", columns: []column{
+					{"id", "id"},
+					{"operation_id", "integer"},
+					{"kind", "string"},
+					{"star_id", "integer"},
+					{"tag_id", "integer"},
+					{"full_name", "string"},
+					{"source", "string"},
+					{"old_value", "string"},
+					{"new_value", "string"},
+				}},
+			} {
+				if err := table.migrate(db); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2,
+		Name:    "fill in star owners",
+		Up: func(db *gorm.DB) error {
+			owners := make(map[uint]string)
+			rows, err := db.Raw("SELECT id, full_name FROM stars WHERE (owner IS NULL OR owner = '') AND full_name LIKE ?", "%/%").Rows()
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var id uint
+				var fullName string
+				if err := rows.Scan(&id, &fullName); err != nil {
+					return err
+				}
+				owners[id] = strings.SplitN(fullName, "/", 2)[0]
+			}
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			for id, owner := range owners {
+				if err := db.Exec("UPDATE stars SET owner = ? WHERE id = ?", owner, id).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 3,
+		Name:    "link tags to their parents",
+		Up: func(db *gorm.DB) error {
+			type tag struct {
+				id   uint
+				name string
+			}
+			var tags []tag
+			rows, err := db.Raw("SELECT id, name FROM tags WHERE deleted_at IS NULL AND parent_id IS NULL AND name LIKE ? ORDER BY name", "%/%").Rows()
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var t tag
+				if err := rows.Scan(&t.id, &t.name); err != nil {
+					return err
+				}
+				tags = append(tags, t)
+			}
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			for _, t := range tags {
+				n := strings.LastIndex(t.name, "/")
+				if n <= 0 {
+					continue
+				}
+				// Creates any ancestors that don't exist yet, linked to theirs
+				parentID, err := findOrCreateParentTag(db, t.name[:n])
+				if err != nil {
+					return err
+				}
+				if err := db.Exec("UPDATE tags SET parent_id = ? WHERE id = ?", parentID, t.id).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 4,
+		Name:    "record whether updates finished",
+		Up: func(db *gorm.DB) error {
+			updateRuns := table{name: "update_runs", columns: []column{{"complete", "bool"}}}
+			return updateRuns.migrate(db)
+		},
+	},
+}
+
+// findOrCreateParentTag finds a tag by name or alias as migration 3 saw them, creating it and any
+// missing ancestors if it doesn't exist, and returns its ID
+func findOrCreateParentTag(db *gorm.DB, name string) (uint, error) {
+	for _, query := range []string{
+		"SELECT id FROM tags WHERE deleted_at IS NULL AND lower(name) = ? ORDER BY id",
+		"SELECT tag_id FROM tag_aliases WHERE deleted_at IS NULL AND lower(name) = ? ORDER BY id",
+	} {
+		ids, err := queryIDs(db, query, strings.ToLower(name))
+		if err != nil {
+			return 0, err
+		}
+		if len(ids) > 0 {
+			return ids[0], nil
+		}
+	}
+
+	var parentID *uint
+	if n := strings.LastIndex(name, "/"); n > 0 {
+		id, err := findOrCreateParentTag(db, name[:n])
+		if err != nil {
+			return 0, err
+		}
+		parentID = &id
+	}
+	now := time.Now()
+	if err := db.Exec("INSERT INTO tags (created_at, updated_at, name, parent_id) VALUES (?, ?, ?, ?)",
+		now, now, name, parentID).Error; err != nil {
+		return 0, err
+	}
+	ids, err := queryIDs(db, "SELECT id FROM tags WHERE deleted_at IS NULL AND name = ? ORDER BY id DESC", name)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("tag '%s' wasn't created", name)
+	}
+	return ids[0], nil
+}
+
+func queryIDs(db *gorm.DB, query string, values ...interface{}) ([]uint, error) {
+	rows, err := db.Raw(query, values...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// column is a column a migration creates, with a kind the database's dialect turns into a type
+type column struct {
+	name string
+	kind string
+}
+
+// table is a table a migration creates, or brings up to date by adding the columns it's missing
+type table struct {
+	name       string
+	columns    []column
+	primaryKey []string
+	// index is the column to index, or empty to index deleted_at if the table has it
+	index string
+}
+
+// withModel returns the columns of a gorm.Model followed by the specified columns
+func withModel(columns ...column) []column {
+	return append([]column{
+		{"id", "id"},
+		{"created_at", "time"},
+		{"updated_at", "time"},
+		{"deleted_at", "time"},
+	}, columns...)
+}
+
+// columnTypes are the types for each column kind, by dialect
+var columnTypes = map[string]map[string]string{
+	"sqlite3": {
+		"id":      "integer primary key autoincrement",
+		"integer": "integer",
+		"string":  "varchar(255)",
+		"text":    "text",
+		"bool":    "bool",
+		"time":    "datetime",
+	},
+	"postgres": {
+		"id":      "serial primary key",
+		"integer": "integer",
+		"string":  "varchar(255)",
+		"text":    "text",
+		"bool":    "boolean",
+		"time":    "timestamp with time zone",
+	},
+}
+
+func columnType(db *gorm.DB, kind string) (string, error) {
+	dialect := db.Dialect().GetName()
+	types, ok := columnTypes[dialect]
+	if !ok {
+		return "", fmt.Errorf("migrations don't support the %s database", dialect)
+	}
+	return types[kind], nil
+}
+
+func (t *table) migrate(db *gorm.DB) error {
+	exists, err := hasTable(db, t.name)
+	if err != nil {
+		return err
+	}
+
+	var definitions []string
+	for _, c := range t.columns {
+		kind, err := columnType(db, c.kind)
+		if err != nil {
+			return err
+		}
+		definition := fmt.Sprintf("%q %s", c.name, kind)
+		if !exists {
+			definitions = append(definitions, definition)
+			continue
+		}
+		has, err := hasColumn(db, t.name, c.name)
+		if err != nil {
+			return err
+		}
+		if !has {
+			if err := db.Exec(fmt.Sprintf("ALTER TABLE %q ADD COLUMN %s", t.name, definition)).Error; err != nil {
+				return err
+			}
+		}
+	}
+	if !exists {
+		if len(t.primaryKey) > 0 {
+			definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", quoteNames(t.primaryKey)))
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE TABLE %q (%s)", t.name, strings.Join(definitions, ", "))).Error; err != nil {
+			return err
+		}
+	}
+
+	index := t.index
+	if index == "" {
+		for _, c := range t.columns {
+			if c.name == "deleted_at" {
+				index = c.name
+			}
+		}
+	}
+	if index == "" {
+		return nil
+	}
+	return db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %q ON %q (%q)",
+		fmt.Sprintf("idx_%s_%s", t.name, index), t.name, index)).Error
+}
+
+func quoteNames(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// hasTable and hasColumn look at the schema through db, so they see what a transaction has changed
+func hasTable(db *gorm.DB, name string) (bool, error) {
+	query := "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	if db.Dialect().GetName() == "postgres" {
+		query = "SELECT count(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	}
+	var count int
+	err := db.Raw(query, name).Row().Scan(&count)
+	return count > 0, err
+}
+
+func hasColumn(db *gorm.DB, table string, name string) (bool, error) {
+	var count int
+	var err error
+	if db.Dialect().GetName() == "postgres" {
+		err = db.Raw("SELECT count(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?",
+			table, name).Row().Scan(&count)
+	} else {
+		err = db.Raw("SELECT count(*) FROM pragma_table_info(?) WHERE name = ?", table, name).Row().Scan(&count)
+	}
+	return count > 0, err
+}
+
+// FindMigrationStatus finds each migration and whether it's been applied
+func FindMigrationStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	schemaMigrations := table{name: "schema_migrations", primaryKey: []string{"version"}, columns: []column{
+		{"version", "integer"},
+		{"name", "string"},
+		{"applied_at", "time"},
+	}}
+	if err := schemaMigrations.migrate(db); err != nil {
+		return nil, err
+	}
+
+	var applied []SchemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int]time.Time)
+	for _, migration := range applied {
+		appliedAt[migration.Version] = migration.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Migration: migration}
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// HasPendingMigrations returns whether the database has migrations to apply, without changing it
+func HasPendingMigrations(db *gorm.DB) (bool, error) {
+	if !db.HasTable(&SchemaMigration{}) {
+		return true, nil
+	}
+
+	var applied int
+	if err := db.Model(&SchemaMigration{}).Count(&applied).Error; err != nil {
+		return false, err
+	}
+	return applied < len(migrations), nil
+}
+
+// Migrate applies the pending migrations in order and returns the ones it applied. If the database
+// at url is a SQLite database that already has tables, it first copies it to a backup file, and
+// returns the backup's path
+func Migrate(db *gorm.DB, url string) ([]Migration, string, error) {
+	hasTables := db.HasTable(&Star{})
+
+	statuses, err := FindMigrationStatus(db)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, "", nil
+	}
+
+	backup := ""
+	if dialect, filepath := ParseDatabaseURL(url); hasTables && dialect == "sqlite3" && filepath != "" {
+		backup = fmt.Sprintf("%s.%s.bak", filepath, time.Now().Format("20060102150405"))
+		if err := copyFile(filepath, backup); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Each migration and the record of it commit together, so a failed migration changes nothing
+	var applied []Migration
+	for _, migration := range pending {
+		if err := applyMigration(db, migration); err != nil {
+			return applied, backup, fmt.Errorf("migration %d (%s) failed: %s", migration.Version, migration.Name, err.Error())
+		}
+		applied = append(applied, migration)
+	}
+	return applied, backup, nil
+}
+
+func applyMigration(db *gorm.DB, migration Migration) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := migration.Up(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Create(&SchemaMigration{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		AppliedAt: time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateShouldApplyEveryMigrationToAnEmptyDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "limo.db")
+	empty, err := OpenDB(path, false)
+	assert.Nil(t, err)
+	defer empty.Close()
+
+	applied, backup, err := Migrate(empty, path)
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), len(applied))
+	assert.Equal(t, "", backup)
+	assert.True(t, empty.HasTable(&Star{}))
+	assert.True(t, empty.HasTable(&OperationStep{}))
+
+	statuses, err := FindMigrationStatus(empty)
+	assert.Nil(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt)
+	}
+
+	applied, _, err = Migrate(empty, path)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(applied))
+}
+
+func TestMigrateShouldMigrateTheSchemaFromBeforeMigrations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	schema, err := ioutil.ReadFile(filepath.Join("testdata", "schema.sql"))
+	assert.Nil(t, err)
+
+	path := filepath.Join(dir, "limo.db")
+	fixture, err := OpenDB(path, false)
+	assert.Nil(t, err)
+	defer fixture.Close()
+	assert.Nil(t, fixture.Exec(string(schema)).Error)
+
+	statuses, err := FindMigrationStatus(fixture)
+	assert.Nil(t, err)
+	for _, status := range statuses {
+		assert.Nil(t, status.AppliedAt)
+	}
+
+	applied, backup, err := Migrate(fixture, path)
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), len(applied))
+	assert.NotEqual(t, "", backup)
+	_, err = os.Stat(backup)
+	assert.Nil(t, err)
+
+	star, err := FindStarByID(fixture, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "hoop33", *star.Owner)
+	assert.Nil(t, star.LoadTags(fixture))
+	assert.Equal(t, 2, len(star.Tags))
+
+	// Tags named with slashes are linked to their parents, creating any that are missing
+	for name, parentName := range map[string]string{
+		"lang/go":     "lang",
+		"editors/vim": "editors",
+	} {
+		tag, err := FindTagByName(fixture, name)
+		assert.Nil(t, err)
+		parent, err := FindTagByName(fixture, parentName)
+		assert.Nil(t, err)
+		assert.NotNil(t, parent)
+		assert.NotNil(t, tag.ParentID)
+		assert.Equal(t, parent.ID, *tag.ParentID)
+	}
+	cli, err := FindTagByName(fixture, "cli")
+	assert.Nil(t, err)
+	assert.Nil(t, cli.ParentID)
+}
+
+func TestMigrateShouldChangeNothingWhenAMigrationFails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "limo")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "limo.db")
+	db, err := OpenDB(path, false)
+	assert.Nil(t, err)
+	defer db.Close()
+
+	original := migrations
+	defer func() { migrations = original }()
+	migrations = append(original[:len(original):len(original)], Migration{
+		Version: len(original) + 1,
+		Name:    "fail partway",
+		Up: func(db *gorm.DB) error {
+			if err := db.Exec("CREATE TABLE partway (id integer)").Error; err != nil {
+				return err
+			}
+			return errors.New("failed")
+		},
+	})
+
+	applied, _, err := Migrate(db, path)
+	assert.NotNil(t, err)
+	assert.Equal(t, len(original), len(applied))
+	assert.False(t, db.HasTable("partway"))
+
+	statuses, err := FindMigrationStatus(db)
+	assert.Nil(t, err)
+	assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+}
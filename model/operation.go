@@ -0,0 +1,523 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Kinds of steps an operation can take
+const (
+	StepCreateTag  = "create-tag"
+	StepAddTag     = "add-tag"
+	StepRemoveTag  = "remove-tag"
+	StepRenameTag  = "rename-tag"
+	StepDeleteTag  = "delete-tag"
+	StepDeleteStar = "delete-star"
+	StepMoveAlias  = "move-alias"
+	StepMoveList   = "move-list"
+	StepMergeTag   = "merge-tag"
+)
+
+// Operation records a command that changed your local database, with the steps it took,
+// so it can be undone
+type Operation struct {
+	gorm.Model
+	Command  string
+	UndoneAt *time.Time
+	Steps    []OperationStep `gorm:"-"`
+}
+
+// OperationStep is one change an operation made, with enough of the before and after state to invert it
+type OperationStep struct {
+	ID          uint `gorm:"primary_key"`
+	OperationID uint `gorm:"index"`
+	Kind        string
+	StarID      uint
+	TagID       uint
+	FullName    string
+	Source      string
+	OldValue    string
+	NewValue    string
+}
+
+// NewOperation starts recording an operation for a command. Nothing is saved until the operation takes a step
+func NewOperation(command string) *Operation {
+	return &Operation{Command: command}
+}
+
+// record saves a step, saving the operation first if this is its first step.
+// A nil operation records nothing
+func (op *Operation) record(db *gorm.DB, step OperationStep) error {
+	if op == nil {
+		return nil
+	}
+	if op.ID == 0 {
+		if err := db.Create(op).Error; err != nil {
+			return err
+		}
+	}
+	step.OperationID = op.ID
+	if err := db.Create(&step).Error; err != nil {
+		return err
+	}
+	op.Steps = append(op.Steps, step)
+	return nil
+}
+
+// findStarTag finds the association between a star and a tag, or nil if there isn't one
+func findStarTag(db *gorm.DB, starID uint, tagID uint) (*StarTag, error) {
+	var starTag StarTag
+	if db.Where("star_id = ? AND tag_id = ?", starID, tagID).First(&starTag).RecordNotFound() {
+		return nil, db.Error
+	}
+	return &starTag, db.Error
+}
+
+// inTransaction makes a change and records its steps in one transaction, so the change is
+// saved only with the steps to undo it. If either fails, neither is saved
+func (op *Operation) inTransaction(db *gorm.DB, change func(tx *gorm.DB) error) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	var original Operation
+	if op != nil {
+		original = *op
+	}
+	if err := change(tx); err != nil {
+		tx.Rollback()
+		if op != nil {
+			*op = original
+		}
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// findMissingTags finds which of a tag and its ancestors don't exist yet, from the top down
+func findMissingTags(db *gorm.DB, name string) ([]string, error) {
+	var missing []string
+	for n := CleanTagName(name); n != ""; n = parentTagName(n) {
+		existing, err := FindTagByName(db, n)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			break
+		}
+		missing = append([]string{n}, missing...)
+	}
+	return missing, nil
+}
+
+// recordCreatedTags records the tags of the missing ones that exist now
+func (op *Operation) recordCreatedTags(db *gorm.DB, missing []string) error {
+	for _, n := range missing {
+		tag, err := FindTagByName(db, n)
+		if err != nil {
+			return err
+		}
+		if tag != nil {
+			if err := op.record(db, OperationStep{Kind: StepCreateTag, TagID: tag.ID, NewValue: tag.Name}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// FindOrCreateTagByName finds or creates a tag like FindOrCreateTagByName, recording the tags it creates
+func (op *Operation) FindOrCreateTagByName(db *gorm.DB, name string) (*Tag, bool, error) {
+	var tag *Tag
+	var created bool
+	err := op.inTransaction(db, func(tx *gorm.DB) error {
+		missing, err := findMissingTags(tx, name)
+		if err != nil {
+			return err
+		}
+		if tag, created, err = FindOrCreateTagByName(tx, name); err != nil {
+			return err
+		}
+		return op.recordCreatedTags(tx, missing)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return tag, created, nil
+}
+
+// AddTag adds a tag to a star like AddTag, recording it if the star didn't already have the tag
+func (op *Operation) AddTag(db *gorm.DB, star *Star, tag *Tag) error {
+	return op.inTransaction(db, func(tx *gorm.DB) error {
+		existing, err := findStarTag(tx, star.ID, tag.ID)
+		if err != nil {
+			return err
+		}
+		if err := star.AddTag(tx, tag); err != nil {
+			return err
+		}
+		if existing != nil {
+			return nil
+		}
+		return op.record(tx, OperationStep{Kind: StepAddTag, StarID: star.ID, TagID: tag.ID, FullName: stringOrEmpty(star.FullName), NewValue: tag.Name})
+	})
+}
+
+// AddTagFromSource adds a tag to a star like AddTagFromSource, recording it with its source
+func (op *Operation) AddTagFromSource(db *gorm.DB, star *Star, tag *Tag, source string) error {
+	return op.inTransaction(db, func(tx *gorm.DB) error {
+		if err := star.AddTagFromSource(tx, tag, source); err != nil {
+			return err
+		}
+		return op.record(tx, OperationStep{Kind: StepAddTag, StarID: star.ID, TagID: tag.ID, FullName: stringOrEmpty(star.FullName), Source: source, NewValue: tag.Name})
+	})
+}
+
+// RemoveTag removes a tag from a star like RemoveTag, recording where the tag came from
+func (op *Operation) RemoveTag(db *gorm.DB, star *Star, tag *Tag) error {
+	return op.inTransaction(db, func(tx *gorm.DB) error {
+		existing, err := findStarTag(tx, star.ID, tag.ID)
+		if err != nil {
+			return err
+		}
+		if err := star.RemoveTag(tx, tag); err != nil {
+			return err
+		}
+		if existing == nil {
+			return nil
+		}
+		return op.record(tx, OperationStep{Kind: StepRemoveTag, StarID: star.ID, TagID: tag.ID, FullName: stringOrEmpty(star.FullName), Source: existing.Source, OldValue: tag.Name})
+	})
+}
+
+// RemoveAllTags removes all tags from a star like RemoveAllTags, recording each of them
+func (op *Operation) RemoveAllTags(db *gorm.DB, star *Star) error {
+	return op.inTransaction(db, func(tx *gorm.DB) error {
+		var starTags []StarTag
+		if err := tx.Where("star_id = ?", star.ID).Order("tag_id").Find(&starTags).Error; err != nil {
+			return err
+		}
+		if err := star.RemoveAllTags(tx); err != nil {
+			return err
+		}
+		for _, starTag := range starTags {
+			name := ""
+			if tag, err := FindTagByID(tx, starTag.TagID); err == nil {
+				name = tag.Name
+			}
+			if err := op.record(tx, OperationStep{Kind: StepRemoveTag, StarID: star.ID, TagID: starTag.TagID,
+				FullName: stringOrEmpty(star.FullName), Source: starTag.Source, OldValue: name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RenameTag renames a tag like Rename, recording its old name and the parent tags the new name creates
+func (op *Operation) RenameTag(db *gorm.DB, tag *Tag, name string) error {
+	original := *tag
+	err := op.inTransaction(db, func(tx *gorm.DB) error {
+		missing, err := findMissingTags(tx, parentTagName(CleanTagName(name)))
+		if err != nil {
+			return err
+		}
+		if err := tag.rename(tx, name); err != nil {
+			return err
+		}
+		if err := op.recordCreatedTags(tx, missing); err != nil {
+			return err
+		}
+		return op.record(tx, OperationStep{Kind: StepRenameTag, TagID: tag.ID, OldValue: original.Name, NewValue: tag.Name})
+	})
+	if err != nil {
+		*tag = original
+	}
+	return err
+}
+
+// DeleteTag deletes a tag like Delete. Undoing it restores the tag from the trash
+func (op *Operation) DeleteTag(db *gorm.DB, tag *Tag) error {
+	return op.inTransaction(db, func(tx *gorm.DB) error {
+		if err := tag.Delete(tx); err != nil {
+			return err
+		}
+		return op.record(tx, OperationStep{Kind: StepDeleteTag, TagID: tag.ID, OldValue: tag.Name})
+	})
+}
+
+// MergeTags merges tags into a tag like Merge, recording where each tag's stars, aliases, and lists went
+func (op *Operation) MergeTags(db *gorm.DB, tag *Tag, others []*Tag) ([]uint, error) {
+	var starIDs []uint
+	err := op.inTransaction(db, func(tx *gorm.DB) error {
+		seen := make(map[uint]bool)
+		for _, other := range uniqueTags(others) {
+			IDs, err := op.mergeTag(tx, tag, other)
+			if err != nil {
+				return err
+			}
+			for _, ID := range IDs {
+				if !seen[ID] {
+					seen[ID] = true
+					starIDs = append(starIDs, ID)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return starIDs, nil
+}
+
+// mergeTag merges one tag into another, then records the steps to take it back out
+func (op *Operation) mergeTag(db *gorm.DB, tag *Tag, other *Tag) ([]uint, error) {
+	var starTags []StarTag
+	if err := db.Where("tag_id = ?", other.ID).Order("star_id").Find(&starTags).Error; err != nil {
+		return nil, err
+	}
+	var tagged []uint
+	if err := db.Table("star_tags").Where("tag_id = ?", tag.ID).Pluck("star_id", &tagged).Error; err != nil {
+		return nil, err
+	}
+	hasTag := make(map[uint]bool)
+	for _, ID := range tagged {
+		hasTag[ID] = true
+	}
+	aliases, err := other.FindAliases(db)
+	if err != nil {
+		return nil, err
+	}
+	var mappings []ListMapping
+	if err := db.Where("tag_id = ?", other.ID).Find(&mappings).Error; err != nil {
+		return nil, err
+	}
+
+	starIDs, err := tag.merge(db, []*Tag{other})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, starTag := range starTags {
+		var fullName string
+		if star, err := FindStarByID(db, starTag.StarID); err == nil {
+			fullName = stringOrEmpty(star.FullName)
+		}
+		if err := op.record(db, OperationStep{Kind: StepRemoveTag, StarID: starTag.StarID, TagID: other.ID,
+			FullName: fullName, Source: starTag.Source, OldValue: other.Name}); err != nil {
+			return nil, err
+		}
+		if !hasTag[starTag.StarID] {
+			if err := op.record(db, OperationStep{Kind: StepAddTag, StarID: starTag.StarID, TagID: tag.ID,
+				FullName: fullName, NewValue: tag.Name}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, alias := range aliases {
+		if err := op.record(db, OperationStep{Kind: StepMoveAlias, TagID: other.ID, OldValue: alias.Name, NewValue: tag.Name}); err != nil {
+			return nil, err
+		}
+	}
+	for _, mapping := range mappings {
+		// The step's source holds the list's service ID
+		if err := op.record(db, OperationStep{Kind: StepMoveList, TagID: other.ID, Source: strconv.FormatUint(uint64(mapping.ServiceID), 10),
+			OldValue: mapping.RemoteID, NewValue: tag.Name}); err != nil {
+			return nil, err
+		}
+	}
+	return starIDs, op.record(db, OperationStep{Kind: StepMergeTag, TagID: other.ID, OldValue: other.Name, NewValue: tag.Name})
+}
+
+// DeleteStar deletes a star like Delete. Undoing it restores the star from the trash
+func (op *Operation) DeleteStar(db *gorm.DB, star *Star) error {
+	return op.inTransaction(db, func(tx *gorm.DB) error {
+		if err := star.Delete(tx); err != nil {
+			return err
+		}
+		return op.record(tx, OperationStep{Kind: StepDeleteStar, StarID: star.ID, FullName: stringOrEmpty(star.FullName)})
+	})
+}
+
+// String describes the step
+func (step *OperationStep) String() string {
+	switch step.Kind {
+	case StepCreateTag:
+		return fmt.Sprintf("created tag '%s'", step.NewValue)
+	case StepAddTag:
+		return fmt.Sprintf("added tag '%s' to %s", step.NewValue, step.FullName)
+	case StepRemoveTag:
+		return fmt.Sprintf("removed tag '%s' from %s", step.OldValue, step.FullName)
+	case StepRenameTag:
+		return fmt.Sprintf("renamed tag '%s' to '%s'", step.OldValue, step.NewValue)
+	case StepDeleteTag:
+		return fmt.Sprintf("deleted tag '%s'", step.OldValue)
+	case StepMoveAlias:
+		return fmt.Sprintf("moved alias '%s' to tag '%s'", step.OldValue, step.NewValue)
+	case StepMoveList:
+		return fmt.Sprintf("moved list '%s' to tag '%s'", step.OldValue, step.NewValue)
+	case StepMergeTag:
+		return fmt.Sprintf("merged tag '%s' into '%s'", step.OldValue, step.NewValue)
+	default:
+		return fmt.Sprintf("deleted star '%s'", step.FullName)
+	}
+}
+
+// LoadSteps loads the operation's steps
+func (op *Operation) LoadSteps(db *gorm.DB) error {
+	return db.Where("operation_id = ?", op.ID).Order("id").Find(&op.Steps).Error
+}
+
+// FindOperations finds the operations, most recent first
+func FindOperations(db *gorm.DB) ([]Operation, error) {
+	return findOperations(db, db)
+}
+
+// FindOperationByID finds an operation by ID, with its steps
+func FindOperationByID(db *gorm.DB, ID uint) (*Operation, error) {
+	var op Operation
+	if db.First(&op, ID).RecordNotFound() {
+		return nil, fmt.Errorf("operation '%d' not found", ID)
+	}
+	return &op, op.LoadSteps(db)
+}
+
+// FindUndoableOperations finds up to n of the most recent operations that haven't been undone, most recent first
+func FindUndoableOperations(db *gorm.DB, n int) ([]Operation, error) {
+	return findOperations(db, db.Where("undone_at IS NULL").Limit(n))
+}
+
+func findOperations(db *gorm.DB, scope *gorm.DB) ([]Operation, error) {
+	var ops []Operation
+	if err := scope.Order("id desc").Find(&ops).Error; err != nil {
+		return nil, err
+	}
+	for i := range ops {
+		if err := ops[i].LoadSteps(db); err != nil {
+			return nil, err
+		}
+	}
+	return ops, nil
+}
+
+// Undo inverts the operation's steps, last first, and returns the IDs of the stars whose tags changed.
+// If any step fails, none of them are undone
+func (op *Operation) Undo(db *gorm.DB) ([]uint, error) {
+	if op.UndoneAt != nil {
+		return nil, fmt.Errorf("'%s' has already been undone", op.Command)
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	var starIDs []uint
+	for i := len(op.Steps) - 1; i >= 0; i-- {
+		IDs, err := op.Steps[i].undo(tx)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		starIDs = append(starIDs, IDs...)
+	}
+
+	now := time.Now()
+	if err := tx.Model(op).UpdateColumn("undone_at", now).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	op.UndoneAt = &now
+	return starIDs, nil
+}
+
+// undo inverts a step and returns the IDs of the stars whose tags changed
+func (step *OperationStep) undo(db *gorm.DB) ([]uint, error) {
+	switch step.Kind {
+	case StepCreateTag:
+		// Leave the tag if something else has used it since
+		var uses int
+		if err := db.Model(&StarTag{}).Where("tag_id = ?", step.TagID).Count(&uses).Error; err != nil {
+			return nil, err
+		}
+		var children int
+		if err := db.Model(&Tag{}).Where("parent_id = ?", step.TagID).Count(&children).Error; err != nil {
+			return nil, err
+		}
+		if uses > 0 || children > 0 {
+			return nil, nil
+		}
+		return nil, db.Unscoped().Where("id = ?", step.TagID).Delete(&Tag{}).Error
+	case StepAddTag:
+		return []uint{step.StarID}, db.Where("star_id = ? AND tag_id = ?", step.StarID, step.TagID).Delete(&StarTag{}).Error
+	case StepRemoveTag:
+		existing, err := findStarTag(db, step.StarID, step.TagID)
+		if err != nil || existing != nil {
+			return nil, err
+		}
+		// Leave it off if the star or tag has been deleted since
+		if _, err := FindStarByID(db, step.StarID); err != nil {
+			return nil, nil
+		}
+		if _, err := FindTagByID(db, step.TagID); err != nil {
+			return nil, nil
+		}
+		return []uint{step.StarID}, db.Create(&StarTag{StarID: step.StarID, TagID: step.TagID, Source: step.Source}).Error
+	case StepRenameTag:
+		tag, err := FindTagByID(db, step.TagID)
+		if err != nil {
+			return nil, err
+		}
+		var starIDs []uint
+		if err := db.Table("star_tags").Where("tag_id = ?", tag.ID).Pluck("star_id", &starIDs).Error; err != nil {
+			return nil, err
+		}
+		return starIDs, tag.rename(db, step.OldValue)
+	case StepDeleteTag:
+		var tag Tag
+		if db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", step.TagID).First(&tag).RecordNotFound() {
+			return nil, fmt.Errorf("tag '%s' is no longer in the trash", step.OldValue)
+		}
+		return tag.Restore(db)
+	case StepMoveAlias:
+		return nil, db.Model(&TagAlias{}).Where("name = ?", step.OldValue).UpdateColumn("tag_id", step.TagID).Error
+	case StepMoveList:
+		serviceID, err := strconv.ParseUint(step.Source, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		var mapping ListMapping
+		if db.Where("service_id = ? AND remote_id = ?", serviceID, step.OldValue).First(&mapping).RecordNotFound() {
+			// The merge dropped it, since the tag it went into already had a list on the service
+			return nil, db.Create(&ListMapping{TagID: step.TagID, ServiceID: uint(serviceID), RemoteID: step.OldValue}).Error
+		}
+		return nil, db.Model(&mapping).UpdateColumn("tag_id", step.TagID).Error
+	case StepMergeTag:
+		existing, err := FindTagByName(db, step.OldValue)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && strings.EqualFold(existing.Name, step.OldValue) {
+			return nil, fmt.Errorf("tag '%s' already exists", existing.Name)
+		}
+		if err := db.Unscoped().Where("lower(name) = ?", strings.ToLower(step.OldValue)).Delete(&TagAlias{}).Error; err != nil {
+			return nil, err
+		}
+		return nil, db.Unscoped().Model(&Tag{}).Where("id = ?", step.TagID).UpdateColumn("deleted_at", nil).Error
+	default:
+		var star Star
+		if db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", step.StarID).First(&star).RecordNotFound() {
+			return nil, fmt.Errorf("star '%s' is no longer in the trash", step.FullName)
+		}
+		return []uint{star.ID}, star.Restore(db)
+	}
+}
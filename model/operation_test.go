@@ -0,0 +1,261 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUndoShouldPutBackRemovedTags(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	name := "a/b"
+	star := &Star{RemoteID: "ab", FullName: &name}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	cli, _, err := FindOrCreateTagByName(db, "cli")
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTagFromSource(db, cli, TopicSource))
+	vim, _, err := FindOrCreateTagByName(db, "vim")
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTag(db, vim))
+
+	op := NewOperation("untag a/b")
+	assert.Nil(t, op.RemoveAllTags(db, star))
+	assert.Equal(t, 2, len(op.Steps))
+
+	ops, err := FindUndoableOperations(db, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ops))
+	assert.Equal(t, "untag a/b", ops[0].Command)
+
+	starIDs, err := ops[0].Undo(db)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{star.ID, star.ID}, starIDs)
+
+	assert.Nil(t, star.LoadTags(db))
+	assert.Equal(t, 2, len(star.Tags))
+	starTag, err := findStarTag(db, star.ID, cli.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, TopicSource, starTag.Source)
+
+	ops, err = FindUndoableOperations(db, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ops))
+}
+
+func TestUndoShouldRemoveAddedAndCreatedTags(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	name := "a/b"
+	star := &Star{RemoteID: "ab", FullName: &name}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	_, _, err = FindOrCreateTagByName(db, "lang")
+	assert.Nil(t, err)
+
+	op := NewOperation("tag a/b lang/go/web")
+	tag, created, err := op.FindOrCreateTagByName(db, "lang/go/web")
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Nil(t, op.AddTag(db, star, tag))
+	assert.Equal(t, 3, len(op.Steps))
+	assert.Equal(t, "created tag 'lang/go'", op.Steps[0].String())
+	assert.Equal(t, "added tag 'lang/go/web' to a/b", op.Steps[2].String())
+
+	_, err = op.Undo(db)
+	assert.Nil(t, err)
+
+	tags, err := FindTags(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tags))
+	assert.Equal(t, "lang", tags[0].Name)
+
+	_, err = op.Undo(db)
+	assert.NotNil(t, err)
+}
+
+func TestUndoShouldReverseRenameAndDelete(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "www")
+	assert.Nil(t, err)
+
+	rename := NewOperation("rename www web")
+	assert.Nil(t, rename.RenameTag(db, tag, "web"))
+	remove := NewOperation("delete tag web")
+	assert.Nil(t, remove.DeleteTag(db, tag))
+
+	ops, err := FindUndoableOperations(db, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ops))
+	for _, op := range ops {
+		_, err = op.Undo(db)
+		assert.Nil(t, err)
+	}
+
+	restored, err := FindTagByName(db, "www")
+	assert.Nil(t, err)
+	assert.NotNil(t, restored)
+	assert.Equal(t, tag.ID, restored.ID)
+}
+
+func TestUndoShouldRemoveParentTagsCreatedByRename(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "go")
+	assert.Nil(t, err)
+
+	op := NewOperation("rename go lang/go")
+	assert.Nil(t, op.RenameTag(db, tag, "lang/go"))
+	assert.Equal(t, 2, len(op.Steps))
+	assert.Equal(t, "created tag 'lang'", op.Steps[0].String())
+
+	_, err = op.Undo(db)
+	assert.Nil(t, err)
+
+	tag, err = FindTagByID(db, tag.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "go", tag.Name)
+	lang, err := FindTagByName(db, "lang")
+	assert.Nil(t, err)
+	assert.Nil(t, lang)
+}
+
+func TestOperationShouldNotChangeTagsWhenRecordingFails(t *testing.T) {
+	clearDB()
+
+	name := "a/star"
+	star := &Star{RemoteID: name, FullName: &name}
+	assert.Nil(t, db.Create(star).Error)
+	tag, _, err := FindOrCreateTagByName(db, "cli")
+	assert.Nil(t, err)
+
+	db.Callback().Create().Before("gorm:create").Register("limo:fail_step", func(scope *gorm.Scope) {
+		if _, ok := scope.Value.(*OperationStep); ok {
+			_ = scope.Err(errors.New("failed"))
+		}
+	})
+	defer db.Callback().Create().Remove("limo:fail_step")
+
+	op := NewOperation("tag a/star cli")
+	assert.NotNil(t, op.AddTag(db, star, tag))
+	assert.Equal(t, uint(0), op.ID)
+	assert.Nil(t, star.LoadTags(db))
+	assert.Equal(t, 0, len(star.Tags))
+
+	_, _, err = op.FindOrCreateTagByName(db, "lang/go")
+	assert.NotNil(t, err)
+	lang, err := FindTagByName(db, "lang")
+	assert.Nil(t, err)
+	assert.Nil(t, lang)
+
+	ops, err := FindOperations(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ops))
+}
+
+func TestUndoShouldUnmergeTags(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	both, only := "a/b", "c/d"
+	bothStar := &Star{RemoteID: "ab", FullName: &both}
+	_, err = CreateOrUpdateStar(db, bothStar, service)
+	assert.Nil(t, err)
+	onlyStar := &Star{RemoteID: "cd", FullName: &only}
+	_, err = CreateOrUpdateStar(db, onlyStar, service)
+	assert.Nil(t, err)
+
+	js, _, err := FindOrCreateTagByName(db, "js")
+	assert.Nil(t, err)
+	javascript, _, err := FindOrCreateTagByName(db, "javascript")
+	assert.Nil(t, err)
+	assert.Nil(t, bothStar.AddTag(db, js))
+	assert.Nil(t, bothStar.AddTag(db, javascript))
+	assert.Nil(t, onlyStar.AddTagFromSource(db, js, TopicSource))
+	assert.Nil(t, js.AddAlias(db, "ecmascript"))
+	_, err = CreateOrUpdateListMapping(db, js, service, "js-list")
+	assert.Nil(t, err)
+
+	op := NewOperation("tag merge javascript js")
+	starIDs, err := op.MergeTags(db, javascript, []*Tag{js})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(starIDs))
+
+	_, err = op.Undo(db)
+	assert.Nil(t, err)
+
+	restored, err := FindTagByName(db, "js")
+	assert.Nil(t, err)
+	assert.Equal(t, js.ID, restored.ID)
+	alias, err := FindTagByName(db, "ecmascript")
+	assert.Nil(t, err)
+	assert.Equal(t, js.ID, alias.ID)
+	mapping, err := FindListMappingByRemoteID(db, "js-list", service)
+	assert.Nil(t, err)
+	assert.Equal(t, js.ID, mapping.TagID)
+
+	assert.Nil(t, bothStar.LoadTags(db))
+	assert.Equal(t, 2, len(bothStar.Tags))
+	assert.Nil(t, onlyStar.LoadTags(db))
+	assert.Equal(t, 1, len(onlyStar.Tags))
+	assert.Equal(t, "js", onlyStar.Tags[0].Name)
+	starTag, err := findStarTag(db, onlyStar.ID, js.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, TopicSource, starTag.Source)
+}
+
+func TestUndoShouldChangeNothingWhenAStepFails(t *testing.T) {
+	clearDB()
+
+	old, _, err := FindOrCreateTagByName(db, "old")
+	assert.Nil(t, err)
+	www, _, err := FindOrCreateTagByName(db, "www")
+	assert.Nil(t, err)
+
+	op := NewOperation("tag changes")
+	assert.Nil(t, op.DeleteTag(db, old))
+	assert.Nil(t, op.RenameTag(db, www, "web"))
+
+	// Purging the deleted tag means its step can't be undone
+	_, _, err = PurgeTrash(db, time.Now().Add(time.Minute))
+	assert.Nil(t, err)
+
+	_, err = op.Undo(db)
+	assert.NotNil(t, err)
+	assert.Nil(t, op.UndoneAt)
+
+	tag, err := FindTagByID(db, www.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "web", tag.Name)
+
+	ops, err := FindUndoableOperations(db, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ops))
+}
+
+func TestNilOperationShouldNotRecord(t *testing.T) {
+	clearDB()
+
+	var op *Operation
+	_, _, err := op.FindOrCreateTagByName(db, "solo")
+	assert.Nil(t, err)
+
+	ops, err := FindOperations(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(ops))
+}
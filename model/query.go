@@ -0,0 +1,465 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Query selects stars with an expression, as in:
+// tag:cli and (language:go or language:rust) and not tag:deprecated and stars>1000 and starred>2023-01-01
+type Query struct {
+	Text string
+	root queryNode
+}
+
+// queryOps are the operators each kind of field supports in a query
+var queryOps = map[fieldKind]map[string]bool{
+	stringField: {":": true, "=": true, "!=": true},
+	listField:   {":": true, "=": true, "!=": true},
+	numberField: {":": true, "=": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true},
+	boolField:   {":": true, "=": true, "!=": true},
+	dateField:   {"<": true, "<=": true, ">": true, ">=": true},
+}
+
+type queryField struct {
+	kind   fieldKind
+	column string
+}
+
+var queryFields = map[string]queryField{
+	"tag":      {listField, ""},
+	"topic":    {listField, "TOPICS"},
+	"service":  {stringField, ""},
+	"language": {stringField, "LANGUAGE"},
+	"owner":    {stringField, "OWNER"},
+	"license":  {stringField, "LICENSE"},
+	"stars":    {numberField, "STARGAZERS"},
+	"forks":    {numberField, "FORKS"},
+	"issues":   {numberField, "OPEN_ISSUES"},
+	"rating":   {numberField, "RATING"},
+	"starred":  {dateField, "STARRED_AT"},
+	"pushed":   {dateField, "PUSHED_AT"},
+	"created":  {dateField, "REMOTE_CREATED_AT"},
+	"archived": {boolField, "ARCHIVED"},
+	"fork":     {boolField, "FORK"},
+}
+
+// relativeDate matches dates relative to now, like 30d, 2w, 6m, or 1y
+var relativeDate = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
+// parseAgo parses a relative date into years, months, and days, or returns nil if it isn't one
+func parseAgo(text string) []int {
+	parts := relativeDate.FindStringSubmatch(text)
+	if parts == nil {
+		return nil
+	}
+
+	n, _ := strconv.Atoi(parts[1])
+	switch parts[2] {
+	case "d":
+		return []int{0, 0, n}
+	case "w":
+		return []int{0, 0, 7 * n}
+	case "m":
+		return []int{0, n, 0}
+	default:
+		return []int{n, 0, 0}
+	}
+}
+
+// ParseTimeAgo parses a relative date like 30d, 2w, 6m, or 1y into the time that long before now
+func ParseTimeAgo(text string, now time.Time) (time.Time, error) {
+	ago := parseAgo(text)
+	if ago == nil {
+		return now, fmt.Errorf("'%s' isn't a time ago like 30d, 2w, 6m, or 1y", text)
+	}
+	return now.AddDate(-ago[0], -ago[1], -ago[2]), nil
+}
+
+// ParseQuery parses a query
+func ParseQuery(text string) (*Query, error) {
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{ruleParser{tokens: tokens}}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, p.errorf("unexpected '%s'", p.peek().text)
+	}
+	return &Query{
+		Text: text,
+		root: root,
+	}, nil
+}
+
+// String returns the parsed form of the query, with explicit grouping
+func (query *Query) String() string {
+	return query.root.String()
+}
+
+// SQL compiles the query to a condition on STARS S and its parameters, resolving relative dates from now
+func (query *Query) SQL(now time.Time) (string, []interface{}) {
+	return query.root.sql(now.UTC().Truncate(time.Second))
+}
+
+// WithLanguage returns a query that also requires a language or, with union, that accepts either
+func (query *Query) WithLanguage(language string, union bool) *Query {
+	term := &termQuery{
+		name:  "language",
+		field: queryFields["language"],
+		op:    ":",
+		text:  language,
+	}
+
+	var root queryNode = &andQuery{query.root, term}
+	if union {
+		root = &orQuery{query.root, term}
+	}
+	return &Query{
+		Text: query.Text,
+		root: root,
+	}
+}
+
+// CountStarsByQuery counts the stars that match a query
+func CountStarsByQuery(db *gorm.DB, query *Query) (int, error) {
+	where, args := query.SQL(time.Now())
+
+	var count int
+	db.Raw(fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM STARS S
+		WHERE S.DELETED_AT IS NULL
+		AND %s`, where), args...).Count(&count)
+	return count, db.Error
+}
+
+// FindStarsByQuery finds the stars that match a query
+func FindStarsByQuery(db *gorm.DB, query *Query, match string) ([]Star, error) {
+	where, args := query.SQL(time.Now())
+
+	// The query's SQL refers to the stars table as S, which gorm's soft delete scoping doesn't
+	// allow for, so run it raw
+	if match != "" {
+		where = fmt.Sprintf("LOWER(S.FULL_NAME) LIKE ? AND %s", where)
+		args = append([]interface{}{fmt.Sprintf("%%%s%%", strings.ToLower(match))}, args...)
+	}
+
+	var stars []Star
+	db.Raw(fmt.Sprintf(`
+		SELECT *
+		FROM STARS S
+		WHERE S.DELETED_AT IS NULL
+		AND %s
+		ORDER BY S.FULL_NAME`, where), args...).Scan(&stars)
+	return stars, db.Error
+}
+
+// queryNode is a node in a query's expression
+type queryNode interface {
+	sql(now time.Time) (string, []interface{})
+	String() string
+}
+
+type andQuery struct {
+	left, right queryNode
+}
+
+func (n *andQuery) sql(now time.Time) (string, []interface{}) {
+	return joinSQL(now, "AND", n.left, n.right)
+}
+
+func (n *andQuery) String() string {
+	return fmt.Sprintf("(and %s %s)", n.left, n.right)
+}
+
+type orQuery struct {
+	left, right queryNode
+}
+
+func (n *orQuery) sql(now time.Time) (string, []interface{}) {
+	return joinSQL(now, "OR", n.left, n.right)
+}
+
+func (n *orQuery) String() string {
+	return fmt.Sprintf("(or %s %s)", n.left, n.right)
+}
+
+func joinSQL(now time.Time, operator string, left, right queryNode) (string, []interface{}) {
+	leftSQL, leftArgs := left.sql(now)
+	rightSQL, rightArgs := right.sql(now)
+	return fmt.Sprintf("(%s %s %s)", leftSQL, operator, rightSQL), append(leftArgs, rightArgs...)
+}
+
+type notQuery struct {
+	node queryNode
+}
+
+// sql negates the node so that NULL counts as false, the way != does: NOT on its own would drop
+// the stars whose column is NULL, because NOT NULL is NULL. CASE works in both SQLite and PostgreSQL
+func (n *notQuery) sql(now time.Time) (string, []interface{}) {
+	where, args := n.node.sql(now)
+	return fmt.Sprintf("(CASE WHEN %s THEN 1 ELSE 0 END) = 0", where), args
+}
+
+func (n *notQuery) String() string {
+	return fmt.Sprintf("(not %s)", n.node)
+}
+
+type termQuery struct {
+	name   string
+	field  queryField
+	op     string
+	text   string
+	number int
+	flag   bool
+	date   time.Time
+	ago    []int // years, months, and days before now, for relative dates
+}
+
+// tagSubtreeSQL selects the IDs of a tag, found by name or alias, and its descendants
+const tagSubtreeSQL = `S.ID %s (
+	WITH RECURSIVE SUBTREE(ID) AS (
+		SELECT ID FROM TAGS
+		WHERE DELETED_AT IS NULL
+		AND (LOWER(NAME) = ? OR ID IN (SELECT TAG_ID FROM TAG_ALIASES WHERE DELETED_AT IS NULL AND LOWER(NAME) = ?))
+		UNION
+		SELECT T.ID FROM TAGS T
+		INNER JOIN SUBTREE ON T.PARENT_ID = SUBTREE.ID
+		WHERE T.DELETED_AT IS NULL
+	)
+	SELECT STAR_ID FROM STAR_TAGS WHERE TAG_ID IN (SELECT ID FROM SUBTREE))`
+
+func (n *termQuery) sql(now time.Time) (string, []interface{}) {
+	negate := n.op == "!="
+	op := n.op
+	switch op {
+	case ":":
+		op = "="
+	case "!=":
+		op = "<>"
+	}
+
+	switch {
+	case n.name == "tag":
+		in := "IN"
+		if negate {
+			in = "NOT IN"
+		}
+		name := strings.ToLower(n.text)
+		return fmt.Sprintf(tagSubtreeSQL, in), []interface{}{name, name}
+	case n.name == "service":
+		in := "IN"
+		if negate {
+			in = "NOT IN"
+		}
+		return fmt.Sprintf("S.SERVICE_ID %s (SELECT ID FROM SERVICES WHERE LOWER(NAME) = ?)", in),
+			[]interface{}{strings.ToLower(n.text)}
+	case n.name == "topic":
+		// Topics are stored separated by commas
+		pattern := fmt.Sprintf("%%,%s,%%", strings.ToLower(n.text))
+		if negate {
+			return "(S.TOPICS IS NULL OR (',' || LOWER(S.TOPICS) || ',') NOT LIKE ?)", []interface{}{pattern}
+		}
+		return "(',' || LOWER(S.TOPICS) || ',') LIKE ?", []interface{}{pattern}
+	case n.field.kind == stringField:
+		if negate {
+			return fmt.Sprintf("(S.%s IS NULL OR LOWER(S.%s) <> ?)", n.field.column, n.field.column),
+				[]interface{}{strings.ToLower(n.text)}
+		}
+		return fmt.Sprintf("LOWER(S.%s) = ?", n.field.column), []interface{}{strings.ToLower(n.text)}
+	case n.field.kind == numberField:
+		return fmt.Sprintf("S.%s %s ?", n.field.column, op), []interface{}{n.number}
+	case n.field.kind == boolField:
+		return fmt.Sprintf("S.%s %s ?", n.field.column, op), []interface{}{n.flag}
+	default:
+		date := n.date
+		if n.ago != nil {
+			date = now.AddDate(-n.ago[0], -n.ago[1], -n.ago[2])
+		}
+		return fmt.Sprintf("S.%s %s ?", n.field.column, op), []interface{}{date}
+	}
+}
+
+func (n *termQuery) String() string {
+	return fmt.Sprintf("(%s %s %s)", n.name, n.op, n.text)
+}
+
+type queryParser struct {
+	ruleParser
+}
+
+func (p *queryParser) acceptWord(word string) bool {
+	if token := p.peek(); token.kind == wordToken && strings.EqualFold(token.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// startsTerm returns whether the next token starts another term, so terms next to each other are ANDed
+func (p *queryParser) startsTerm() bool {
+	token := p.peek()
+	if token.kind == symbolToken {
+		return token.text == "("
+	}
+	return token.kind == wordToken && !strings.EqualFold(token.text, "or")
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptWord("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orQuery{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptWord("and") || p.startsTerm() {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andQuery{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.acceptWord("not") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notQuery{node}, nil
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return node, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *queryParser) parseTerm() (queryNode, error) {
+	token := p.next()
+	name := strings.ToLower(token.text)
+	if token.kind != wordToken || name == "and" || name == "or" {
+		return nil, errorAt(token, "expected a field")
+	}
+	field, ok := queryFields[name]
+	if !ok {
+		return nil, errorAt(token, fmt.Sprintf("unknown field '%s'", token.text))
+	}
+
+	opToken := p.next()
+	value := p.next()
+	node := &termQuery{
+		name:  name,
+		field: field,
+		op:    opToken.text,
+		text:  value.text,
+	}
+
+	invalid := errorAt(opToken, fmt.Sprintf("can't compare '%s' with '%s %s'", token.text, opToken.text, value.text))
+	if opToken.kind != symbolToken || !queryOps[field.kind][node.op] {
+		return nil, invalid
+	}
+	if value.kind != wordToken && value.kind != stringToken {
+		return nil, invalid
+	}
+
+	var err error
+	switch field.kind {
+	case numberField:
+		if node.number, err = strconv.Atoi(value.text); err != nil {
+			return nil, invalid
+		}
+	case boolField:
+		if node.flag, err = strconv.ParseBool(value.text); err != nil {
+			return nil, invalid
+		}
+	case dateField:
+		if node.ago = parseAgo(value.text); node.ago == nil {
+			if node.date, err = time.Parse("2006-01-02", value.text); err != nil {
+				return nil, invalid
+			}
+		}
+	}
+	return node, nil
+}
+
+// querySymbols, longest first so ">=" wins over ">"
+var querySymbols = []string{"!=", ">=", "<=", ":", "=", ">", "<", "(", ")"}
+
+func lexQuery(text string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			value, size, err := lexQuoted(runes[i:], r)
+			if err != nil {
+				return nil, fmt.Errorf("%s at %d", err.Error(), i)
+			}
+			tokens = append(tokens, ruleToken{stringToken, value, i})
+			i += size
+		case isQueryWordRune(r):
+			start := i
+			for i < len(runes) && isQueryWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, ruleToken{wordToken, string(runes[start:i]), start})
+		default:
+			symbol := ""
+			for _, s := range querySymbols {
+				if strings.HasPrefix(string(runes[i:]), s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("unexpected '%c' at %d", r, i)
+			}
+			tokens = append(tokens, ruleToken{symbolToken, symbol, i})
+			i += len([]rune(symbol))
+		}
+	}
+	return append(tokens, ruleToken{endToken, "", len(runes)}), nil
+}
+
+// isQueryWordRune allows words like c++, c#, and lang/go as values
+func isQueryWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`"():=!<>`, r)
+}
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueryShouldGroupByPrecedence(t *testing.T) {
+	for text, expected := range map[string]string{
+		`tag:cli`: `(tag : cli)`,
+		`tag:cli and (language:go or language:rust) and not tag:deprecated and stars>1000 and starred>2023-01-01`: `(and (and (and (and (tag : cli) (or (language : go) (language : rust))) (not (tag : deprecated))) (stars > 1000)) (starred > 2023-01-01))`,
+		`language:go or language:rust and stars>=10`:                                                              `(or (language : go) (and (language : rust) (stars >= 10)))`,
+		`tag:cli language:go`:                       `(and (tag : cli) (language : go))`,
+		`NOT not archived:true`:                     `(not (not (archived : true)))`,
+		`language:c++ or language:"objective c"`:    `(or (language : c++) (language : objective c))`,
+		`tag:lang/go topic!=web forks<=5`:           `(and (and (tag : lang/go) (topic != web)) (forks <= 5))`,
+		`(tag:a or tag:b) and (tag:c or not tag:d)`: `(and (or (tag : a) (tag : b)) (or (tag : c) (not (tag : d))))`,
+	} {
+		query, err := ParseQuery(text)
+		if assert.Nil(t, err, text) {
+			assert.Equal(t, expected, query.String(), text)
+		}
+	}
+}
+
+func TestParseQueryShouldReturnErrors(t *testing.T) {
+	for text, message := range map[string]string{
+		``:                       "expected a field at 0",
+		`color:red`:              "unknown field 'color' at 0",
+		`tag:`:                   "can't compare 'tag' with ': ' at 3",
+		`tag>cli`:                "can't compare 'tag' with '> cli' at 3",
+		`stars>many`:             "can't compare 'stars' with '> many' at 5",
+		`starred:2023-01-01`:     "can't compare 'starred' with ': 2023-01-01' at 7",
+		`starred>yesterday`:      "can't compare 'starred' with '> yesterday' at 7",
+		`archived:maybe`:         "can't compare 'archived' with ': maybe' at 8",
+		`(tag:cli`:               "expected ')' at 8",
+		`tag:cli)`:               "unexpected ')' at 7",
+		`tag:cli and`:            "expected a field at 11",
+		`tag:cli or or tag:go`:   "expected a field at 11",
+		`language:"objective c`:  "missing closing \" at 9",
+		`tag:cli and (stars>1 )`: "",
+	} {
+		_, err := ParseQuery(text)
+		if message == "" {
+			assert.Nil(t, err, text)
+		} else if assert.NotNil(t, err, text) {
+			assert.Equal(t, message, err.Error(), text)
+		}
+	}
+}
+
+func TestQueryShouldCompileToParameterizedSQL(t *testing.T) {
+	now := time.Date(2023, 3, 31, 12, 0, 0, 0, time.UTC)
+	for text, expected := range map[string]struct {
+		sql  string
+		args []interface{}
+	}{
+		`language:Go`: {
+			"LOWER(S.LANGUAGE) = ?",
+			[]interface{}{"go"},
+		},
+		`language!=go or license:MIT`: {
+			"((S.LANGUAGE IS NULL OR LOWER(S.LANGUAGE) <> ?) OR LOWER(S.LICENSE) = ?)",
+			[]interface{}{"go", "mit"},
+		},
+		`not stars>1000 and archived:false`: {
+			"((CASE WHEN S.STARGAZERS > ? THEN 1 ELSE 0 END) = 0 AND S.ARCHIVED = ?)",
+			[]interface{}{1000, false},
+		},
+		`issues!=0`: {
+			"S.OPEN_ISSUES <> ?",
+			[]interface{}{0},
+		},
+		`starred>2023-01-01 pushed<=1m created<2w`: {
+			"((S.STARRED_AT > ? AND S.PUSHED_AT <= ?) AND S.REMOTE_CREATED_AT < ?)",
+			[]interface{}{
+				time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, 3, 3, 12, 0, 0, 0, time.UTC),
+				time.Date(2023, 3, 17, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		`topic:CLI topic!=web`: {
+			"((',' || LOWER(S.TOPICS) || ',') LIKE ? AND (S.TOPICS IS NULL OR (',' || LOWER(S.TOPICS) || ',') NOT LIKE ?))",
+			[]interface{}{"%,cli,%", "%,web,%"},
+		},
+		`service!=GitLab`: {
+			"S.SERVICE_ID NOT IN (SELECT ID FROM SERVICES WHERE LOWER(NAME) = ?)",
+			[]interface{}{"gitlab"},
+		},
+		`tag:Lang/Go`: {
+			fmt.Sprintf(tagSubtreeSQL, "IN"),
+			[]interface{}{"lang/go", "lang/go"},
+		},
+	} {
+		query, err := ParseQuery(text)
+		if assert.Nil(t, err, text) {
+			sql, args := query.SQL(now)
+			assert.Equal(t, expected.sql, sql, text)
+			assert.Equal(t, expected.args, args, text)
+		}
+	}
+}
+
+func TestFindStarsByQueryShouldFindMatchingStars(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+
+	goLang, rust := "Go", "Rust"
+	for i, star := range []struct {
+		language *string
+		stars    int
+		starred  time.Time
+		topics   Topics
+		tags     []string
+	}{
+		{&goLang, 5000, time.Now().AddDate(0, 0, -1), Topics{"cli"}, []string{"lang/go/cli"}},
+		{&goLang, 50, time.Now().AddDate(0, 0, -60), nil, []string{"lang/go", "deprecated"}},
+		{&rust, 2000, time.Now().AddDate(-1, 0, 0), Topics{"cli", "tui"}, []string{"cli"}},
+		{nil, 10, time.Now(), nil, nil},
+	} {
+		fullName := fmt.Sprintf("star%d", i)
+		s := &Star{
+			RemoteID:   fullName,
+			FullName:   &fullName,
+			Language:   star.language,
+			Stargazers: star.stars,
+			StarredAt:  star.starred.UTC(),
+			Topics:     star.topics,
+		}
+		_, err = CreateOrUpdateStar(db, s, service)
+		assert.Nil(t, err)
+		for _, name := range star.tags {
+			tag, _, err := FindOrCreateTagByName(db, name)
+			assert.Nil(t, err)
+			assert.Nil(t, s.AddTag(db, tag))
+		}
+	}
+
+	tag, err := FindTagByName(db, "cli")
+	assert.Nil(t, err)
+	assert.Nil(t, tag.AddAlias(db, "command-line"))
+
+	for text, expected := range map[string][]string{
+		`tag:lang/go`:                           {"star0", "star1"},
+		`tag:lang and not tag:deprecated`:       {"star0"},
+		`tag:command-line`:                      {"star2"},
+		`language:go or language:rust`:          {"star0", "star1", "star2"},
+		`language!=go`:                          {"star2", "star3"},
+		`not language:go`:                       {"star2", "star3"},
+		`not topic:tui`:                         {"star0", "star1", "star3"},
+		`not (language:rust or stars>1000)`:     {"star1", "star3"},
+		`stars>1000 and starred>30d`:            {"star0"},
+		`topic:cli and not topic:tui`:           {"star0"},
+		`service:github and starred<2000-01-01`: nil,
+	} {
+		query, err := ParseQuery(text)
+		assert.Nil(t, err, text)
+
+		stars, err := FindStarsByQuery(db, query, "")
+		assert.Nil(t, err, text)
+
+		var names []string
+		for _, star := range stars {
+			names = append(names, *star.FullName)
+		}
+		assert.Equal(t, expected, names, text)
+	}
+
+	query, err := ParseQuery(`language:go`)
+	assert.Nil(t, err)
+	stars, err := FindStarsByQuery(db, query, "star1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stars))
+}
@@ -0,0 +1,504 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Rule tags the stars that match its condition, as in: language == "Go" && description =~ /(?i)cli/ => cli, go-tools
+type Rule struct {
+	Text      string
+	Tags      []string
+	condition ruleNode
+}
+
+// RuleMatch contains the tags that rules would add to a star
+type RuleMatch struct {
+	Star *Star
+	Tags []string
+}
+
+type fieldKind int
+
+const (
+	stringField fieldKind = iota
+	listField
+	numberField
+	boolField
+	dateField
+)
+
+// fieldOps are the operators each kind of field supports
+var fieldOps = map[fieldKind]map[string]bool{
+	stringField: {"==": true, "!=": true, "=~": true, "!~": true},
+	listField:   {"==": true, "!=": true, "=~": true, "!~": true},
+	numberField: {"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true},
+	boolField:   {"==": true, "!=": true},
+}
+
+type ruleField struct {
+	kind  fieldKind
+	value func(*Star) interface{}
+}
+
+func stringValue(s *string) interface{} {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+var ruleFields = map[string]ruleField{
+	"name":        {stringField, func(s *Star) interface{} { return stringValue(s.Name) }},
+	"fullname":    {stringField, func(s *Star) interface{} { return stringValue(s.FullName) }},
+	"description": {stringField, func(s *Star) interface{} { return stringValue(s.Description) }},
+	"homepage":    {stringField, func(s *Star) interface{} { return stringValue(s.Homepage) }},
+	"url":         {stringField, func(s *Star) interface{} { return stringValue(s.URL) }},
+	"language":    {stringField, func(s *Star) interface{} { return stringValue(s.Language) }},
+	"owner":       {stringField, func(s *Star) interface{} { return stringValue(s.Owner) }},
+	"license":     {stringField, func(s *Star) interface{} { return stringValue(s.License) }},
+	"topic":       {listField, func(s *Star) interface{} { return []string(s.Topics) }},
+	"tag": {listField, func(s *Star) interface{} {
+		names := make([]string, 0, len(s.Tags))
+		for _, tag := range s.Tags {
+			names = append(names, tag.Name)
+		}
+		return names
+	}},
+	"stars":    {numberField, func(s *Star) interface{} { return s.Stargazers }},
+	"forks":    {numberField, func(s *Star) interface{} { return s.Forks }},
+	"issues":   {numberField, func(s *Star) interface{} { return s.OpenIssues }},
+	"archived": {boolField, func(s *Star) interface{} { return s.Archived }},
+	"fork":     {boolField, func(s *Star) interface{} { return s.Fork }},
+}
+
+// ParseRule parses a rule
+func ParseRule(text string) (*Rule, error) {
+	tokens, err := lexRule(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ruleParser{tokens: tokens}
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.accept("=>") {
+		return nil, p.errorf("expected '=>'")
+	}
+
+	rule := &Rule{
+		Text:      text,
+		condition: condition,
+	}
+	for {
+		token := p.next()
+		if token.kind != wordToken && token.kind != stringToken {
+			return nil, p.errorf("expected a tag")
+		}
+		rule.Tags = append(rule.Tags, token.text)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if !p.done() {
+		return nil, p.errorf("unexpected '%s'", p.peek().text)
+	}
+	return rule, nil
+}
+
+// ParseRules parses rules, reporting the first that doesn't parse
+func ParseRules(texts []string) ([]*Rule, error) {
+	rules := make([]*Rule, 0, len(texts))
+	for _, text := range texts {
+		rule, err := ParseRule(text)
+		if err != nil {
+			return nil, fmt.Errorf("rule '%s': %s", text, err.Error())
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Matches returns whether a star matches the rule. Load the star's tags first to match on tags
+func (rule *Rule) Matches(star *Star) bool {
+	return rule.condition.eval(star)
+}
+
+// PlanRules returns the tags that the rules would add to each star that doesn't already have them
+func PlanRules(db *gorm.DB, rules []*Rule, stars []Star) ([]RuleMatch, error) {
+	var matches []RuleMatch
+	for i := range stars {
+		star := &stars[i]
+		if err := star.LoadTags(db); err != nil {
+			return nil, err
+		}
+
+		var names []string
+		seen := make(map[string]bool)
+		for _, rule := range rules {
+			if !rule.Matches(star) {
+				continue
+			}
+			for _, name := range rule.Tags {
+				key := strings.ToLower(name)
+				if !seen[key] && !hasTagFold(star, name) {
+					seen[key] = true
+					names = append(names, name)
+				}
+			}
+		}
+
+		if len(names) > 0 {
+			matches = append(matches, RuleMatch{
+				Star: star,
+				Tags: names,
+			})
+		}
+	}
+	return matches, nil
+}
+
+// Apply adds the matched tags to the star, recording them in an operation if op isn't nil
+func (match *RuleMatch) Apply(db *gorm.DB, op *Operation) error {
+	for _, name := range match.Tags {
+		tag, _, err := op.FindOrCreateTagByName(db, name)
+		if err != nil {
+			return err
+		}
+		if !match.Star.HasTag(tag) {
+			if err := op.AddTag(db, match.Star, tag); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func hasTagFold(star *Star, name string) bool {
+	for _, tag := range star.Tags {
+		if strings.EqualFold(tag.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleNode is a node in a rule's condition
+type ruleNode interface {
+	eval(star *Star) bool
+}
+
+type andNode struct {
+	left, right ruleNode
+}
+
+func (n *andNode) eval(star *Star) bool {
+	return n.left.eval(star) && n.right.eval(star)
+}
+
+type orNode struct {
+	left, right ruleNode
+}
+
+func (n *orNode) eval(star *Star) bool {
+	return n.left.eval(star) || n.right.eval(star)
+}
+
+type notNode struct {
+	node ruleNode
+}
+
+func (n *notNode) eval(star *Star) bool {
+	return !n.node.eval(star)
+}
+
+type compareNode struct {
+	field  ruleField
+	op     string
+	text   string
+	number int
+	flag   bool
+	regexp *regexp.Regexp
+}
+
+func (n *compareNode) eval(star *Star) bool {
+	value := n.field.value(star)
+	switch n.field.kind {
+	case stringField:
+		return n.compareString(value.(string))
+	case listField:
+		// A list matches if any element matches, and doesn't match if no element matches
+		values := value.([]string)
+		negate := n.op == "!=" || n.op == "!~"
+		for _, v := range values {
+			if n.compareString(v) != negate {
+				return !negate
+			}
+		}
+		return negate
+	case numberField:
+		return n.compareNumber(value.(int))
+	default:
+		return (value.(bool) == n.flag) == (n.op == "==")
+	}
+}
+
+func (n *compareNode) compareString(value string) bool {
+	switch n.op {
+	case "==":
+		return strings.EqualFold(value, n.text)
+	case "!=":
+		return !strings.EqualFold(value, n.text)
+	case "=~":
+		return n.regexp.MatchString(value)
+	default:
+		return !n.regexp.MatchString(value)
+	}
+}
+
+func (n *compareNode) compareNumber(value int) bool {
+	switch n.op {
+	case "==":
+		return value == n.number
+	case "!=":
+		return value != n.number
+	case "<":
+		return value < n.number
+	case "<=":
+		return value <= n.number
+	case ">":
+		return value > n.number
+	default:
+		return value >= n.number
+	}
+}
+
+type tokenKind int
+
+const (
+	wordToken tokenKind = iota
+	stringToken
+	regexpToken
+	symbolToken
+	endToken
+)
+
+type ruleToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// symbols, longest first so "==" wins over "="
+var ruleSymbols = []string{"=>", "==", "!=", "=~", "!~", ">=", "<=", "&&", "||", ">", "<", "!", "(", ")", ","}
+
+func lexRule(text string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '/':
+			kind := stringToken
+			if r == '/' {
+				kind = regexpToken
+			}
+			value, size, err := lexQuoted(runes[i:], r)
+			if err != nil {
+				return nil, fmt.Errorf("%s at %d", err.Error(), i)
+			}
+			tokens = append(tokens, ruleToken{kind, value, i})
+			i += size
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, ruleToken{wordToken, string(runes[start:i]), start})
+		default:
+			symbol := ""
+			for _, s := range ruleSymbols {
+				if strings.HasPrefix(string(runes[i:]), s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("unexpected '%c' at %d", r, i)
+			}
+			tokens = append(tokens, ruleToken{symbolToken, symbol, i})
+			i += len([]rune(symbol))
+		}
+	}
+	return append(tokens, ruleToken{endToken, "", len(runes)}), nil
+}
+
+// lexQuoted reads a string or regular expression, where a backslash escapes the quote
+func lexQuoted(runes []rune, quote rune) (string, int, error) {
+	var value []rune
+	for i := 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == quote:
+			value = append(value, quote)
+			i++
+		case runes[i] == quote:
+			return string(value), i + 1, nil
+		default:
+			value = append(value, runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("missing closing %c", quote)
+}
+
+// isWordRune returns whether r can be part of a word. A word can't start with '/', which starts a
+// regular expression, but can contain it, so hierarchical tags like lang/go don't need quotes
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == '/'
+}
+
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+}
+
+func (p *ruleParser) peek() ruleToken {
+	return p.tokens[p.pos]
+}
+
+func (p *ruleParser) next() ruleToken {
+	token := p.tokens[p.pos]
+	if token.kind != endToken {
+		p.pos++
+	}
+	return token
+}
+
+func (p *ruleParser) accept(symbol string) bool {
+	if token := p.peek(); token.kind == symbolToken && token.text == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ruleParser) done() bool {
+	return p.peek().kind == endToken
+}
+
+func (p *ruleParser) errorf(format string, args ...interface{}) error {
+	return errorAt(p.peek(), fmt.Sprintf(format, args...))
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseUnary() (ruleNode, error) {
+	if p.accept("!") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node}, nil
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *ruleParser) parseComparison() (ruleNode, error) {
+	token := p.next()
+	if token.kind != wordToken {
+		return nil, errorAt(token, "expected a field")
+	}
+	field, ok := ruleFields[strings.ToLower(token.text)]
+	if !ok {
+		return nil, errorAt(token, fmt.Sprintf("unknown field '%s'", token.text))
+	}
+
+	opToken := p.next()
+	value := p.next()
+	node := &compareNode{
+		field: field,
+		op:    opToken.text,
+	}
+
+	invalid := errorAt(opToken, fmt.Sprintf("can't compare '%s' with '%s %s'", token.text, opToken.text, value.text))
+	if opToken.kind != symbolToken || !fieldOps[field.kind][node.op] {
+		return nil, invalid
+	}
+
+	var err error
+	switch {
+	case node.op == "=~" || node.op == "!~":
+		if value.kind != regexpToken {
+			return nil, invalid
+		}
+		if node.regexp, err = regexp.Compile(value.text); err != nil {
+			return nil, errorAt(value, err.Error())
+		}
+	case field.kind == numberField:
+		if node.number, err = strconv.Atoi(value.text); err != nil || value.kind != wordToken {
+			return nil, invalid
+		}
+	case field.kind == boolField:
+		if node.flag, err = strconv.ParseBool(value.text); err != nil || value.kind != wordToken {
+			return nil, invalid
+		}
+	default:
+		if value.kind != stringToken && value.kind != wordToken {
+			return nil, invalid
+		}
+		node.text = value.text
+	}
+	return node, nil
+}
+
+func errorAt(token ruleToken, message string) error {
+	return fmt.Errorf("%s at %d", message, token.pos)
+}
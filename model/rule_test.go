@@ -0,0 +1,166 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRuleStar() *Star {
+	name := "terraform"
+	fullName := "hashicorp/terraform"
+	description := "Terraform is a CLI tool for infrastructure as code"
+	language := "Go"
+	owner := "hashicorp"
+	return &Star{
+		Name:        &name,
+		FullName:    &fullName,
+		Description: &description,
+		Language:    &language,
+		Owner:       &owner,
+		Stargazers:  40000,
+		Topics:      Topics{"infrastructure-as-code", "terraform"},
+		Tags:        []Tag{{Name: "devops"}},
+	}
+}
+
+func TestParseRuleShouldParseTags(t *testing.T) {
+	rule, err := ParseRule(`language == "Go" && description =~ /(?i)cli/ => cli, go-tools`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"cli", "go-tools"}, rule.Tags)
+}
+
+func TestParseRuleShouldParseHierarchicalTags(t *testing.T) {
+	rule, err := ParseRule(`tag == lang/go && description =~/(?i)cli/ => lang/go/cli, tools`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"lang/go/cli", "tools"}, rule.Tags)
+
+	star := newRuleStar()
+	star.Tags = []Tag{{Name: "lang/go"}}
+	assert.True(t, rule.Matches(star))
+}
+
+func TestRuleShouldMatch(t *testing.T) {
+	star := newRuleStar()
+	for _, text := range []string{
+		`language == "Go" && description =~ /(?i)cli/ => x`,
+		`owner == hashicorp => x`,
+		`language == "go" => x`,
+		`language == "Rust" || stars > 1000 => x`,
+		`!(language == "Rust") => x`,
+		`topic == terraform => x`,
+		`topic =~ /-as-/ => x`,
+		`topic != rust => x`,
+		`tag == devops => x`,
+		`stars >= 40000 && forks == 0 && archived == false => x`,
+		`name != "vault" && fullname !~ /vault/ => x`,
+		`license == "" => x`,
+	} {
+		rule, err := ParseRule(text)
+		assert.Nil(t, err, text)
+		assert.True(t, rule.Matches(star), text)
+	}
+}
+
+func TestRuleShouldNotMatch(t *testing.T) {
+	star := newRuleStar()
+	for _, text := range []string{
+		`language == "Rust" => x`,
+		`language == "Go" && stars < 100 => x`,
+		`topic == rust => x`,
+		`topic != terraform => x`,
+		`tag !~ /dev/ => x`,
+		`fork == true => x`,
+		`!(owner == hashicorp) => x`,
+	} {
+		rule, err := ParseRule(text)
+		assert.Nil(t, err, text)
+		assert.False(t, rule.Matches(star), text)
+	}
+}
+
+func TestRuleShouldPreferAndOverOr(t *testing.T) {
+	star := newRuleStar()
+	rule, err := ParseRule(`language == "Go" || language == "Rust" && stars < 10 => x`)
+	assert.Nil(t, err)
+	assert.True(t, rule.Matches(star))
+}
+
+func TestParseRuleShouldReturnErrors(t *testing.T) {
+	for text, message := range map[string]string{
+		`language == "Go"`:               "expected '=>' at 16",
+		`language == "Go" =>`:            "expected a tag at 19",
+		`color == "red" => x`:            "unknown field 'color' at 0",
+		`stars =~ /1/ => x`:              "can't compare 'stars' with '=~ 1' at 6",
+		`language > "Go" => x`:           "can't compare 'language' with '> Go' at 9",
+		`stars > many => x`:              "can't compare 'stars' with '> many' at 6",
+		`archived == maybe => x`:         "can't compare 'archived' with '== maybe' at 9",
+		`language == "Go => x`:           "missing closing \" at 12",
+		`description =~ /(/ => x`:        "error parsing regexp: missing closing ): `(` at 15",
+		`(language == "Go" => x`:         "expected ')' at 18",
+		`language == "Go" => x y`:        "unexpected 'y' at 22",
+		`language == "Go" # => x`:        "unexpected '#' at 17",
+		`language == "Go" && => x`:       "expected a field at 20",
+		`language == "Go" => x, "a b"`:   "",
+		`description =~ /a\/b/ => x`:     "",
+		`description == "say \"hi\""=>x`: "",
+	} {
+		_, err := ParseRule(text)
+		if message == "" {
+			assert.Nil(t, err, text)
+		} else if assert.NotNil(t, err, text) {
+			assert.Equal(t, message, err.Error(), text)
+		}
+	}
+}
+
+func TestParseRulesShouldIdentifyBadRule(t *testing.T) {
+	_, err := ParseRules([]string{`owner == a => x`, `owner => x`})
+	assert.NotNil(t, err)
+	assert.Equal(t, "rule 'owner => x': can't compare 'owner' with '=> x' at 6", err.Error())
+}
+
+func TestPlanRulesShouldSkipTagsStarsAlreadyHave(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	goLang, rust := "Go", "Rust"
+	stars := []Star{
+		{RemoteID: "1", Language: &goLang},
+		{RemoteID: "2", Language: &rust},
+	}
+	for i := range stars {
+		_, err = CreateOrUpdateStar(db, &stars[i], service)
+		assert.Nil(t, err)
+	}
+
+	tag, _, err := FindOrCreateTagByName(db, "Go")
+	assert.Nil(t, err)
+	assert.Nil(t, stars[0].AddTag(db, tag))
+
+	rules, err := ParseRules([]string{
+		`language == "Go" => go, cli`,
+		`language == "Go" || language == "Rust" => cli, compiled`,
+	})
+	assert.Nil(t, err)
+
+	matches, err := PlanRules(db, rules, stars)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(matches))
+	assert.Equal(t, []string{"cli", "compiled"}, matches[0].Tags)
+	assert.Equal(t, []string{"cli", "compiled"}, matches[1].Tags)
+
+	for _, match := range matches {
+		assert.Nil(t, match.Apply(db, nil))
+	}
+
+	matches, err = PlanRules(db, rules, stars)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(matches))
+
+	count, err := CountTags(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+}
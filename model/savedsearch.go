@@ -0,0 +1,117 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jinzhu/gorm"
+)
+
+// SavedSearchPrefix marks a saved search used in place of a tag, as in: list stars -t @recent-go
+const SavedSearchPrefix = "@"
+
+// SavedSearch is a named query that acts as a tag whose stars are found when it's used
+type SavedSearch struct {
+	gorm.Model
+	Name      string
+	Query     string `gorm:"type:text"`
+	StarCount int    `gorm:"-"`
+}
+
+// IsSavedSearchName returns whether a tag name refers to a saved search
+func IsSavedSearchName(name string) bool {
+	return strings.HasPrefix(name, SavedSearchPrefix)
+}
+
+// FindSavedSearches finds all saved searches
+func FindSavedSearches(db *gorm.DB) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	db.Order("name").Find(&searches)
+	return searches, db.Error
+}
+
+// FindSavedSearchesWithStarCount finds all saved searches and counts the stars they find now
+func FindSavedSearchesWithStarCount(db *gorm.DB) ([]SavedSearch, error) {
+	searches, err := FindSavedSearches(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range searches {
+		query, err := ParseQuery(searches[i].Query)
+		if err != nil {
+			return nil, fmt.Errorf("saved search '%s': %s", searches[i].Name, err.Error())
+		}
+		if searches[i].StarCount, err = CountStarsByQuery(db, query); err != nil {
+			return nil, err
+		}
+	}
+	return searches, nil
+}
+
+// FindSavedSearchByName finds a saved search by name, with or without its prefix
+func FindSavedSearchByName(db *gorm.DB, name string) (*SavedSearch, error) {
+	var search SavedSearch
+	name = strings.TrimPrefix(name, SavedSearchPrefix)
+	if db.Where("lower(name) = ?", strings.ToLower(name)).First(&search).RecordNotFound() {
+		return nil, db.Error
+	}
+	return &search, db.Error
+}
+
+// SaveSearch saves a query under a name, replacing the query if the name exists. It
+// returns whether it created the saved search
+func SaveSearch(db *gorm.DB, name string, text string) (*SavedSearch, bool, error) {
+	name = strings.TrimPrefix(name, SavedSearchPrefix)
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) != -1 {
+		return nil, false, errors.New("a saved search's name must not be empty or contain spaces")
+	}
+
+	if _, err := ParseQuery(text); err != nil {
+		return nil, false, err
+	}
+
+	search, err := FindSavedSearchByName(db, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	created := search == nil
+	if created {
+		search = &SavedSearch{Name: name}
+	}
+	search.Query = text
+	return search, created, db.Save(search).Error
+}
+
+// Parse parses the saved search's query
+func (search *SavedSearch) Parse() (*Query, error) {
+	return ParseQuery(search.Query)
+}
+
+// Delete deletes a saved search
+func (search *SavedSearch) Delete(db *gorm.DB) error {
+	return db.Delete(search).Error
+}
+
+// FindStarsBySavedSearch finds the stars for a saved search and/or a language
+func FindStarsBySavedSearch(db *gorm.DB, match string, language string, name string, union bool) ([]Star, error) {
+	search, err := FindSavedSearchByName(db, name)
+	if err != nil {
+		return nil, err
+	}
+	if search == nil {
+		return nil, fmt.Errorf("saved search '%s' not found", name)
+	}
+
+	query, err := search.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if language != "" {
+		query = query.WithLanguage(language, union)
+	}
+	return FindStarsByQuery(db, query, match)
+}
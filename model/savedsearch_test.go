@@ -0,0 +1,93 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveSearchShouldCreateAndUpdate(t *testing.T) {
+	clearDB()
+
+	search, created, err := SaveSearch(db, "@recent-go", "language:go starred>30d")
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "recent-go", search.Name)
+
+	search, created, err = SaveSearch(db, "Recent-Go", "language:go starred>60d")
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "recent-go", search.Name)
+
+	searches, err := FindSavedSearches(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(searches))
+	assert.Equal(t, "language:go starred>60d", searches[0].Query)
+
+	assert.Nil(t, search.Delete(db))
+	search, err = FindSavedSearchByName(db, "@recent-go")
+	assert.Nil(t, err)
+	assert.Nil(t, search)
+}
+
+func TestSaveSearchShouldRejectBadNamesAndQueries(t *testing.T) {
+	clearDB()
+
+	_, _, err := SaveSearch(db, "@", "language:go")
+	assert.NotNil(t, err)
+
+	_, _, err = SaveSearch(db, "recent go", "language:go")
+	assert.NotNil(t, err)
+
+	_, _, err = SaveSearch(db, "recent", "color:red")
+	assert.NotNil(t, err)
+	assert.Equal(t, "unknown field 'color' at 0", err.Error())
+}
+
+func TestSavedSearchShouldActAsTag(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	goLang, rust := "Go", "Rust"
+	for _, star := range []*Star{
+		{RemoteID: "1", Language: &goLang, Stargazers: 5000},
+		{RemoteID: "2", Language: &rust, Stargazers: 5000},
+		{RemoteID: "3", Language: &goLang, Stargazers: 5},
+	} {
+		_, err = CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+	}
+
+	_, _, err = SaveSearch(db, "popular", "stars>1000")
+	assert.Nil(t, err)
+
+	stars, err := FindStarsByLanguageAndTag(db, "", "", "@popular", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stars))
+
+	stars, err = FindStarsByLanguageAndTag(db, "", "go", "@popular", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stars))
+
+	stars, err = FindStarsByLanguageAndTag(db, "", "go", "@popular", true)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(stars))
+
+	count, err := CountStarsByLanguageAndTag(db, "rust", "@popular")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	searches, err := FindSavedSearchesWithStarCount(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(searches))
+	assert.Equal(t, 2, searches[0].StarCount)
+
+	_, err = FindStarsByLanguageAndTag(db, "", "", "@nope", false)
+	assert.NotNil(t, err)
+	assert.Equal(t, "saved search '@nope' not found", err.Error())
+
+	_, _, err = FindOrCreateTagByName(db, "@popular")
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/jinzhu/gorm"
@@ -24,3 +25,19 @@ func FindOrCreateServiceByName(db *gorm.DB, name string) (*Service, bool, error)
 	}
 	return &service, false, nil
 }
+
+// FindServiceByID finds a service by ID
+func FindServiceByID(db *gorm.DB, ID uint) (*Service, error) {
+	var service Service
+	if db.First(&service, ID).RecordNotFound() {
+		return nil, fmt.Errorf("service '%d' not found", ID)
+	}
+	return &service, db.Error
+}
+
+// FindServices finds all services
+func FindServices(db *gorm.DB) ([]Service, error) {
+	var services []Service
+	db.Order("name").Find(&services)
+	return services, db.Error
+}
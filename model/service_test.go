@@ -35,3 +35,18 @@ func TestFindOrCreateServiceByNameShouldNotCreateDuplicateNames(t *testing.T) {
 	db.Where("name = ?", "foo").Find(&services)
 	assert.Equal(t, 1, len(services))
 }
+
+func TestFindServicesShouldReturnServicesByName(t *testing.T) {
+	clearDB()
+
+	_, _, err := FindOrCreateServiceByName(db, "gitlab")
+	assert.Nil(t, err)
+	_, _, err = FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+
+	services, err := FindServices(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(services))
+	assert.Equal(t, "github", services[0].Name)
+	assert.Equal(t, "gitlab", services[1].Name)
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// StarSnapshot records a star's counts on a day, so you can see how it changes over time
+type StarSnapshot struct {
+	ID         uint `gorm:"primary_key"`
+	StarID     uint `gorm:"index"`
+	Date       time.Time
+	Stargazers int
+	Forks      int
+	OpenIssues int
+}
+
+// Trend describes how a star's stargazers changed over a window
+type Trend struct {
+	Star    Star
+	Change  int
+	Percent float64
+	History []int
+}
+
+// day returns the start of a time's day, in UTC
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RecordSnapshot records the star's counts for the day, replacing any snapshot from earlier that day
+func (star *Star) RecordSnapshot(db *gorm.DB, now time.Time) error {
+	var snapshot StarSnapshot
+	date := day(now)
+	db.Where("star_id = ? AND date = ?", star.ID, date).First(&snapshot)
+
+	snapshot.StarID = star.ID
+	snapshot.Date = date
+	snapshot.Stargazers = star.Stargazers
+	snapshot.Forks = star.Forks
+	snapshot.OpenIssues = star.OpenIssues
+	return db.Save(&snapshot).Error
+}
+
+// FindSnapshots finds the star's snapshots, oldest first
+func (star *Star) FindSnapshots(db *gorm.DB) ([]StarSnapshot, error) {
+	var snapshots []StarSnapshot
+	db.Where("star_id = ?", star.ID).Order("date").Find(&snapshots)
+	return snapshots, db.Error
+}
+
+// FindTrends finds how each star's stargazers changed since a time, fastest growing first. Each
+// star's change is measured from its last snapshot on or before the time, or its first snapshot after
+func FindTrends(db *gorm.DB, since time.Time) ([]Trend, error) {
+	stars, err := FindStars(db, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []StarSnapshot
+	if err := db.Order("star_id, date").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+
+	byStar := make(map[uint][]StarSnapshot)
+	for _, snapshot := range snapshots {
+		byStar[snapshot.StarID] = append(byStar[snapshot.StarID], snapshot)
+	}
+
+	since = day(since)
+	var trends []Trend
+	for _, star := range stars {
+		history := byStar[star.ID]
+		start := 0
+		for i, snapshot := range history {
+			if snapshot.Date.After(since) {
+				break
+			}
+			start = i
+		}
+		history = history[start:]
+		if len(history) < 2 {
+			continue
+		}
+
+		trend := Trend{Star: star}
+		for _, snapshot := range history {
+			trend.History = append(trend.History, snapshot.Stargazers)
+		}
+		first, last := trend.History[0], trend.History[len(trend.History)-1]
+		trend.Change = last - first
+		if first > 0 {
+			trend.Percent = 100 * float64(trend.Change) / float64(first)
+		}
+		trends = append(trends, trend)
+	}
+
+	// Stars are already ordered by name, so a stable sort keeps ties in name order
+	sort.SliceStable(trends, func(i, j int) bool {
+		return trends[i].Change > trends[j].Change
+	})
+	return trends, nil
+}
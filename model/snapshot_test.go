@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordSnapshotShouldKeepOnePerDay(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "1", Stargazers: 10}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	star.Stargazers = 12
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	star.Stargazers = 8
+	assert.Nil(t, star.RecordSnapshot(db, yesterday))
+
+	snapshots, err := star.FindSnapshots(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(snapshots))
+	assert.Equal(t, 8, snapshots[0].Stargazers)
+	assert.Equal(t, 12, snapshots[1].Stargazers)
+	assert.Equal(t, day(yesterday), snapshots[0].Date.UTC())
+}
+
+func TestFindTrendsShouldMeasureChangeOverWindow(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	now := time.Now()
+	for i, history := range [][]int{
+		{100, 150, 200, 400},
+		{500, 450, 400, 300},
+		{10, 10, 10, 10},
+		{0, 0, 0, 7},
+	} {
+		fullName := fmt.Sprintf("star%d", i)
+		star := &Star{RemoteID: fullName, FullName: &fullName}
+		_, err = CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+
+		// Snapshots 60, 20, and 10 days ago, and today
+		for j, daysAgo := range []int{60, 20, 10, 0} {
+			star.Stargazers = history[j]
+			assert.Nil(t, star.RecordSnapshot(db, now.AddDate(0, 0, -daysAgo)))
+		}
+	}
+	lone := "lone"
+	_, err = CreateOrUpdateStar(db, &Star{RemoteID: lone, FullName: &lone}, service)
+	assert.Nil(t, err)
+
+	trends, err := FindTrends(db, now.AddDate(0, 0, -30))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(trends))
+
+	assert.Equal(t, "star0", *trends[0].Star.FullName)
+	assert.Equal(t, 300, trends[0].Change)
+	assert.Equal(t, 300.0, trends[0].Percent)
+	assert.Equal(t, []int{100, 150, 200, 400}, trends[0].History)
+
+	assert.Equal(t, "star3", *trends[1].Star.FullName)
+	assert.Equal(t, 0.0, trends[1].Percent)
+	assert.Equal(t, "star2", *trends[2].Star.FullName)
+	assert.Equal(t, -200, trends[3].Change)
+
+	trends, err = FindTrends(db, now.AddDate(0, 0, -15))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{150, 200, 400}, trends[0].History)
+}
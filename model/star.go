@@ -1,8 +1,11 @@
 package model
 
 import (
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -17,17 +20,64 @@ import (
 // Star represents a starred repository
 type Star struct {
 	gorm.Model
-	RemoteID    string
-	Name        *string
-	FullName    *string
-	Description *string
-	Homepage    *string
-	URL         *string
-	Language    *string
-	Stargazers  int
-	StarredAt   time.Time
-	ServiceID   uint
-	Tags        []Tag `gorm:"many2many:star_tags;"`
+	RemoteID        string
+	Name            *string
+	FullName        *string
+	Description     *string `gorm:"type:text"`
+	Homepage        *string
+	URL             *string
+	Language        *string
+	Stargazers      int
+	StarredAt       time.Time
+	ServiceID       uint
+	LocalPath       *string
+	Topics          Topics `gorm:"type:text"`
+	License         *string
+	Forks           int
+	OpenIssues      int
+	Archived        bool
+	Fork            bool
+	DefaultBranch   *string
+	PushedAt        *time.Time
+	RemoteCreatedAt *time.Time
+	Owner           *string
+	Note            *string `gorm:"type:text"`
+	Rating          int
+	Tags            []Tag `gorm:"many2many:star_tags;"`
+}
+
+// MaxRating is the highest rating you can give a star
+const MaxRating = 5
+
+// localColumns hold what you've added to a star yourself, which updates from a service must not overwrite
+var localColumns = []string{"local_path", "note", "rating"}
+
+// Topics are the topics a repository is labeled with, stored comma-separated
+type Topics []string
+
+// Value joins the topics for storing
+func (topics Topics) Value() (driver.Value, error) {
+	return strings.Join(topics, ","), nil
+}
+
+// Scan splits the stored topics
+func (topics *Topics) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("can't scan %T into topics", value)
+	}
+
+	*topics = nil
+	if s != "" {
+		*topics = strings.Split(s, ",")
+	}
+	return nil
 }
 
 // StarResult wraps a star and an error
@@ -54,47 +104,100 @@ func NewStarFromGithub(timestamp *github.Timestamp, star github.Repository) (*St
 		starredAt = timestamp.Time
 	}
 
+	var license, owner *string
+	if star.License != nil {
+		license = star.License.SPDXID
+		if license == nil || *license == "" || *license == "NOASSERTION" {
+			license = star.License.Name
+		}
+	}
+	if star.Owner != nil {
+		owner = star.Owner.Login
+	}
+
 	return &Star{
-		RemoteID:    strconv.Itoa(int(*star.ID)),
-		Name:        star.Name,
-		FullName:    star.FullName,
-		Description: star.Description,
-		Homepage:    star.Homepage,
-		URL:         star.CloneURL,
-		Language:    star.Language,
-		Stargazers:  stargazersCount,
-		StarredAt:   starredAt,
+		RemoteID:        strconv.Itoa(int(*star.ID)),
+		Name:            star.Name,
+		FullName:        star.FullName,
+		Description:     star.Description,
+		Homepage:        star.Homepage,
+		URL:             star.CloneURL,
+		Language:        star.Language,
+		Stargazers:      stargazersCount,
+		StarredAt:       starredAt,
+		Topics:          star.Topics,
+		License:         license,
+		Forks:           star.GetForksCount(),
+		OpenIssues:      star.GetOpenIssuesCount(),
+		Archived:        star.GetArchived(),
+		Fork:            star.GetFork(),
+		DefaultBranch:   star.DefaultBranch,
+		PushedAt:        githubTime(star.PushedAt),
+		RemoteCreatedAt: githubTime(star.CreatedAt),
+		Owner:           owner,
 	}, nil
 }
 
+func githubTime(timestamp *github.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+	return &timestamp.Time
+}
+
 // NewStarFromGitlab creates a Star from a Gitlab star
 func NewStarFromGitlab(star gitlab.Project) (*Star, error) {
+	var owner *string
+	if star.Owner != nil {
+		owner = &star.Owner.Username
+	} else if star.Namespace != nil {
+		owner = &star.Namespace.Path
+	}
+
 	return &Star{
-		RemoteID:    strconv.Itoa(star.ID),
-		Name:        &star.Name,
-		FullName:    &star.NameWithNamespace,
-		Description: &star.Description,
-		Homepage:    &star.WebURL,
-		URL:         &star.HTTPURLToRepo,
-		Language:    nil,
-		Stargazers:  star.StarCount,
-		StarredAt:   time.Now(), // OK, so this is a lie, but not in payload
+		RemoteID:        strconv.Itoa(star.ID),
+		Name:            &star.Name,
+		FullName:        &star.NameWithNamespace,
+		Description:     &star.Description,
+		Homepage:        &star.WebURL,
+		URL:             &star.HTTPURLToRepo,
+		Language:        nil,
+		Stargazers:      star.StarCount,
+		StarredAt:       time.Now(), // OK, so this is a lie, but not in payload
+		Topics:          star.TagList,
+		Forks:           star.ForksCount,
+		OpenIssues:      star.OpenIssuesCount,
+		Archived:        star.Archived,
+		Fork:            star.ForkedFromProject != nil,
+		DefaultBranch:   &star.DefaultBranch,
+		PushedAt:        star.LastActivityAt,
+		RemoteCreatedAt: star.CreatedAt,
+		Owner:           owner,
 	}, nil
 }
 
-// CreateOrUpdateStar creates or updates a star and returns true if the star was created (vs updated)
+// CreateOrUpdateStar creates or updates a star, recording a snapshot of its counts for the day,
+// and returns true if the star was created (vs updated)
 func CreateOrUpdateStar(db *gorm.DB, star *Star, service *Service) (bool, error) {
 	// Get existing by remote ID and service ID
 	var existing Star
 	if db.Where("remote_id = ? AND service_id = ?", star.RemoteID, service.ID).First(&existing).RecordNotFound() {
 		star.ServiceID = service.ID
-		err := db.Create(star).Error
-		return err == nil, err
+		if err := db.Create(star).Error; err != nil {
+			return false, err
+		}
+		return true, star.RecordSnapshot(db, time.Now())
 	}
 	star.ID = existing.ID
 	star.ServiceID = service.ID
 	star.CreatedAt = existing.CreatedAt
-	return false, db.Save(star).Error
+	star.LocalPath = existing.LocalPath
+	star.Note = existing.Note
+	star.Rating = existing.Rating
+	if err := db.Omit(localColumns...).Save(star).Error; err != nil {
+		return false, err
+	}
+	return false, star.RecordSnapshot(db, time.Now())
 }
 
 // FindStarByRemoteIDAndService finds a star by remote ID and service
@@ -107,6 +210,15 @@ func FindStarByRemoteIDAndService(db *gorm.DB, remoteID string, service *Service
 	return &star, nil
 }
 
+// FindRemoteIDsByServiceName finds the remote IDs of the stars from the service with the specified name
+func FindRemoteIDsByServiceName(db *gorm.DB, name string) ([]string, error) {
+	var remoteIDs []string
+	return remoteIDs, db.Model(&Star{}).
+		Joins("JOIN services ON services.id = stars.service_id").
+		Where("services.name = ?", name).
+		Pluck("stars.remote_id", &remoteIDs).Error
+}
+
 // FindStarByID finds a star by ID
 func FindStarByID(db *gorm.DB, ID uint) (*Star, error) {
 	var star Star
@@ -123,32 +235,24 @@ func CountStarsByLanguageAndTag(db *gorm.DB, args ...string) (int, error) {
 	language := args[0]
 	tag := args[1]
 
-	if language == "" && tag == "" {
+	if IsSavedSearchName(tag) {
+		stars, err := FindStarsBySavedSearch(db, "", language, tag, false)
+		return len(stars), err
+	} else if language == "" && tag == "" {
 		db.Table("stars").Count(&count)
 	} else if language != "" && tag == "" {
 		db.Table("stars").Where("LOWER(language) = ?", strings.ToLower(language)).Count(&count)
-	} else if language == "" && tag != "" {
-		db.Raw(`
-			SELECT COUNT(DISTINCT S.ID)
-			FROM STARS S, TAGS T
-			INNER JOIN STAR_TAGS ST ON S.ID = ST.STAR_ID 
-			INNER JOIN TAGS ON ST.TAG_ID = T.ID 
-			WHERE S.DELETED_AT IS NULL
-			AND T.DELETED_AT IS NULL
-			AND LOWER(T.NAME) = ?`,
-			strings.ToLower(tag)).Count(&count)
 	} else {
-		db.Raw(`
-			SELECT COUNT(DISTINCT S.ID)
-			FROM STARS S, TAGS T
-			INNER JOIN STAR_TAGS ST ON S.ID = ST.STAR_ID 
-			INNER JOIN TAGS ON ST.TAG_ID = T.ID 
-			WHERE S.DELETED_AT IS NULL
-			AND T.DELETED_AT IS NULL
-			AND LOWER(S.LANGUAGE) = ?
-			AND LOWER(T.NAME) = ?`,
-			strings.ToLower(language),
-			strings.ToLower(tag)).Count(&count)
+		// A tag counts the stars tagged with it or any of its descendants
+		IDs, err := findTagSubtreeIDs(db, tag)
+		if err != nil {
+			return 0, err
+		}
+		scope := db.Model(&Star{}).Where("id IN (SELECT star_id FROM star_tags WHERE tag_id IN (?))", IDs)
+		if language != "" {
+			scope = scope.Where("LOWER(language) = ?", strings.ToLower(language))
+		}
+		scope.Count(&count)
 	}
 
 	return count, db.Error
@@ -157,95 +261,77 @@ func CountStarsByLanguageAndTag(db *gorm.DB, args ...string) (int, error) {
 // FindStars finds all stars
 func FindStars(db *gorm.DB, match string) ([]Star, error) {
 	var stars []Star
-	if match != "" {
-		db.Where("full_name LIKE ?",
-			strings.ToLower(fmt.Sprintf("%%%s%%", match))).Order("full_name").Find(&stars)
-	} else {
-		db.Order("full_name").Find(&stars)
-	}
+	matchingName(db, match).Order("full_name").Find(&stars)
 	return stars, db.Error
 }
 
+// matchingName scopes a query to the stars whose full names contain match, ignoring case, or to all stars if
+// match is empty. Compare with LOWER, because LIKE ignores case in SQLite but not in PostgreSQL
+func matchingName(db *gorm.DB, match string) *gorm.DB {
+	if match == "" {
+		return db
+	}
+	return db.Where("LOWER(full_name) LIKE ?", fmt.Sprintf("%%%s%%", strings.ToLower(match)))
+}
+
 // FindUntaggedStars finds stars without any tags
 func FindUntaggedStars(db *gorm.DB, match string) ([]Star, error) {
 	var stars []Star
-	if match != "" {
-		db.Raw(`
-			SELECT *
-			FROM STARS S
-			WHERE S.DELETED_AT IS NULL
-			AND S.FULL_NAME LIKE ?
-			AND S.ID NOT IN (
-				SELECT STAR_ID
-				FROM STAR_TAGS
-			) ORDER BY S.FULL_NAME`,
-			fmt.Sprintf("%%%s%%", strings.ToLower(match))).Scan(&stars)
-	} else {
-		db.Raw(`
-			SELECT *
-			FROM STARS S
-			WHERE S.DELETED_AT IS NULL
-			AND S.ID NOT IN (
-				SELECT STAR_ID
-				FROM STAR_TAGS
-			) ORDER BY S.FULL_NAME`).Scan(&stars)
-	}
+	matchingName(db, match).Where("id NOT IN (SELECT star_id FROM star_tags)").Order("full_name").Find(&stars)
 	return stars, db.Error
 }
 
-// FindStarsByLanguageAndOrTag finds stars with the specified language and/or the specified tag
+// FindStarsByLanguageAndOrTag finds stars with the specified language and/or the specified tag.
+// A tag matches the stars tagged with it or any of its descendants
 func FindStarsByLanguageAndOrTag(db *gorm.DB, match string, language string, tagName string, union bool) ([]Star, error) {
 	operator := "AND"
 	if union {
 		operator = "OR"
 	}
 
-	var stars []Star
-	if match != "" {
-		db.Raw(fmt.Sprintf(`
-			SELECT * 
-			FROM STARS S, TAGS T 
-			INNER JOIN STAR_TAGS ST ON S.ID = ST.STAR_ID 
-			INNER JOIN TAGS ON ST.TAG_ID = T.ID 
-			WHERE S.DELETED_AT IS NULL
-			AND T.DELETED_AT IS NULL
-			AND LOWER(S.FULL_NAME) LIKE ? 
-			AND (LOWER(T.NAME) = ? 
-			%s LOWER(S.LANGUAGE) = ?) 
-			GROUP BY ST.STAR_ID 
-			ORDER BY S.FULL_NAME`, operator),
-			fmt.Sprintf("%%%s%%", strings.ToLower(match)),
-			strings.ToLower(tagName),
-			strings.ToLower(language)).Scan(&stars)
-	} else {
-		db.Raw(fmt.Sprintf(`
-			SELECT * 
-			FROM STARS S, TAGS T 
-			INNER JOIN STAR_TAGS ST ON S.ID = ST.STAR_ID 
-			INNER JOIN TAGS ON ST.TAG_ID = T.ID 
-			WHERE S.DELETED_AT IS NULL
-			AND T.DELETED_AT IS NULL
-			AND LOWER(T.NAME) = ? 
-			%s LOWER(S.LANGUAGE) = ? 
-			GROUP BY ST.STAR_ID 
-			ORDER BY S.FULL_NAME`, operator),
-			strings.ToLower(tagName),
-			strings.ToLower(language)).Scan(&stars)
+	IDs, err := findTagSubtreeIDs(db, tagName)
+	if err != nil {
+		return nil, err
 	}
+
+	var stars []Star
+	matchingName(db, match).
+		Where(fmt.Sprintf("id IN (SELECT star_id FROM star_tags WHERE tag_id IN (?)) %s LOWER(language) = ?", operator),
+			IDs, strings.ToLower(language)).
+		Order("full_name").Find(&stars)
 	return stars, db.Error
 }
 
+// FindStarsByLanguageAndTag finds stars with the specified language and/or tag, or all stars if neither is specified.
+// A tag that starts with SavedSearchPrefix names a saved search
+func FindStarsByLanguageAndTag(db *gorm.DB, match string, language string, tagName string, union bool) ([]Star, error) {
+	if IsSavedSearchName(tagName) {
+		return FindStarsBySavedSearch(db, match, language, tagName, union)
+	}
+
+	if language != "" && tagName != "" {
+		return FindStarsByLanguageAndOrTag(db, match, language, tagName, union)
+	} else if language != "" {
+		return FindStarsByLanguage(db, match, language)
+	} else if tagName != "" {
+		tag, err := FindTagByName(db, tagName)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil {
+			return nil, fmt.Errorf("tag '%s' not found", tagName)
+		}
+		err = tag.LoadStars(db, match)
+		return tag.Stars, err
+	}
+	return FindStars(db, match)
+}
+
 // FindStarsByLanguage finds stars with the specified language
 func FindStarsByLanguage(db *gorm.DB, match string, language string) ([]Star, error) {
 	var stars []Star
-	if match != "" {
-		db.Where("full_name LIKE ? AND lower(language) = ?",
-			strings.ToLower(fmt.Sprintf("%%%s%%", match)),
-			strings.ToLower(language)).Order("full_name").Find(&stars)
-	} else {
-		db.Where("lower(language) = ?",
-			strings.ToLower(language)).Order("full_name").Find(&stars)
-	}
+	matchingName(db, match).Where("lower(language) = ?",
+		strings.ToLower(language)).Order("full_name").Find(&stars)
 	return stars, db.Error
 }
 
@@ -270,10 +356,10 @@ func FuzzyFindStarsByName(db *gorm.DB, name string) ([]Star, error) {
 		db.Where("lower(name) = ?", strings.ToLower(name)).Order("full_name").Find(&stars)
 	}
 	if len(stars) == 0 {
-		db.Where("full_name LIKE ?", strings.ToLower(fmt.Sprintf("%%%s%%", name))).Order("full_name").Find(&stars)
+		db.Where("lower(full_name) LIKE ?", strings.ToLower(fmt.Sprintf("%%%s%%", name))).Order("full_name").Find(&stars)
 	}
 	if len(stars) == 0 {
-		db.Where("name LIKE ?", strings.ToLower(fmt.Sprintf("%%%s%%", name))).Order("full_name").Find(&stars)
+		db.Where("lower(name) LIKE ?", strings.ToLower(fmt.Sprintf("%%%s%%", name))).Order("full_name").Find(&stars)
 	}
 	return stars, db.Error
 }
@@ -327,6 +413,16 @@ func (star *Star) RemoveTag(db *gorm.DB, tag *Tag) error {
 	return db.Model(star).Association("Tags").Delete(tag).Error
 }
 
+// HasTopic returns whether a star is labeled with a topic
+func (star *Star) HasTopic(topic string) bool {
+	for _, t := range star.Topics {
+		if strings.EqualFold(t, topic) {
+			return true
+		}
+	}
+	return false
+}
+
 // HasTag returns whether a star has a tag. Note that you must call LoadTags first -- no reason to incur a database call each time
 func (star *Star) HasTag(tag *Tag) bool {
 	if len(star.Tags) > 0 {
@@ -339,6 +435,11 @@ func (star *Star) HasTag(tag *Tag) bool {
 	return false
 }
 
+// Type returns the document type, so the index uses the star mapping
+func (star *Star) Type() string {
+	return "Star"
+}
+
 // Index adds the star to the index
 func (star *Star) Index(index bleve.Index, db *gorm.DB) error {
 	if err := star.LoadTags(db); err != nil {
@@ -363,6 +464,66 @@ func (star *Star) OpenInBrowser(preferHomepage bool) error {
 	return open.Start(URL)
 }
 
+// WorkspacePath returns the path to check out the star under root, laid out as host/owner/repo
+func (star *Star) WorkspacePath(root string) (string, error) {
+	if star.URL == nil || *star.URL == "" {
+		if star.Name != nil {
+			return "", fmt.Errorf("no URL for star '%s'", *star.Name)
+		}
+		return "", errors.New("no URL for star")
+	}
+
+	u, err := url.Parse(*star.URL)
+	if err != nil {
+		return "", err
+	}
+
+	repoPath := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if u.Host == "" || repoPath == "" {
+		return "", fmt.Errorf("can't determine path for URL '%s'", *star.URL)
+	}
+
+	// The URL comes from the service, so don't let it reach outside the workspace
+	segments := append([]string{u.Host}, strings.Split(repoPath, "/")...)
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `/\`) {
+			return "", fmt.Errorf("can't check out URL '%s' in the workspace", *star.URL)
+		}
+	}
+	path := filepath.Join(append([]string{root}, segments...)...)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("can't check out URL '%s' in the workspace", *star.URL)
+	}
+	return path, nil
+}
+
+// SetLocalPath records where the star is checked out
+func (star *Star) SetLocalPath(db *gorm.DB, localPath string) error {
+	star.LocalPath = &localPath
+	// Update the column only, so the star's updated time still reflects the last update from the service
+	return db.Model(star).UpdateColumn("local_path", localPath).Error
+}
+
+// SetNote sets the star's note, clearing it if it's blank
+func (star *Star) SetNote(db *gorm.DB, note string) error {
+	if strings.TrimSpace(note) == "" {
+		star.Note = nil
+		return db.Model(star).UpdateColumn("note", gorm.Expr("NULL")).Error
+	}
+	star.Note = &note
+	return db.Model(star).UpdateColumn("note", note).Error
+}
+
+// SetRating sets the star's rating from 1 to MaxRating, or clears it with 0
+func (star *Star) SetRating(db *gorm.DB, rating int) error {
+	if rating < 0 || rating > MaxRating {
+		return fmt.Errorf("rating must be from 1 to %d, or 0 to clear it", MaxRating)
+	}
+	star.Rating = rating
+	return db.Model(star).UpdateColumn("rating", rating).Error
+}
+
 // Delete soft-deletes a star
 func (star *Star) Delete(db *gorm.DB) error {
 	return db.Delete(&star).Error
@@ -417,3 +417,253 @@ func TestCreateOrUpdateStarShouldUpdateStar(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "Updated", *updated.Name)
 }
+
+func TestWorkspacePathShouldUseHostOwnerRepo(t *testing.T) {
+	url := "https://github.com/hoop33/limo.git"
+	star := &Star{URL: &url}
+
+	path, err := star.WorkspacePath("/tmp/workspace")
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/workspace/github.com/hoop33/limo", path)
+}
+
+func TestWorkspacePathShouldReturnErrorWhenNoURL(t *testing.T) {
+	name := "limo"
+	star := &Star{Name: &name}
+
+	_, err := star.WorkspacePath("/tmp/workspace")
+	assert.NotNil(t, err)
+	assert.Equal(t, "no URL for star 'limo'", err.Error())
+}
+
+func TestWorkspacePathShouldRejectPathsOutsideTheWorkspace(t *testing.T) {
+	for _, url := range []string{
+		"https://github.com/../../etc",
+		"https://github.com/hoop33/../../../x",
+		"https://github.com/hoop33//limo",
+		"https://../x/y",
+	} {
+		star := &Star{URL: &url}
+		_, err := star.WorkspacePath("/tmp/workspace")
+		assert.NotNil(t, err, url)
+	}
+}
+
+func TestCreateOrUpdateStarShouldPreserveLocalPath(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	star := &Star{
+		RemoteID: "1",
+	}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+	assert.Nil(t, star.SetLocalPath(db, "/tmp/workspace/limo"))
+
+	_, err = CreateOrUpdateStar(db, &Star{RemoteID: "1"}, service)
+	assert.Nil(t, err)
+
+	updated, err := FindStarByID(db, star.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "/tmp/workspace/limo", *updated.LocalPath)
+}
+
+func TestFindStarsByLanguageAndTagShouldReturnErrorWhenTagNotFound(t *testing.T) {
+	clearDB()
+
+	stars, err := FindStarsByLanguageAndTag(db, "", "", "nope", false)
+	assert.NotNil(t, err)
+	assert.Equal(t, "tag 'nope' not found", err.Error())
+	assert.Equal(t, 0, len(stars))
+}
+
+func TestFindStarsByLanguageAndTagShouldFindByTag(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	fullName := "celtics/larry-bird"
+	star := &Star{RemoteID: "1", FullName: &fullName}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+	_, err = CreateOrUpdateStar(db, &Star{RemoteID: "2"}, service)
+	assert.Nil(t, err)
+
+	tag, _, err := FindOrCreateTagByName(db, "legend")
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTag(db, tag))
+
+	stars, err := FindStarsByLanguageAndTag(db, "", "", "legend", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stars))
+	assert.Equal(t, fullName, *stars[0].FullName)
+
+	stars, err = FindStarsByLanguageAndTag(db, "", "", "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stars))
+}
+
+func TestNewStarFromGithubShouldCopyMetadata(t *testing.T) {
+	id := int64(33)
+	login := "celtics"
+	spdxID := "MIT"
+	branch := "main"
+	forks := 12
+	openIssues := 3
+	archived := true
+	pushedAt := time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2015, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	star, err := NewStarFromGithub(nil, github.Repository{
+		ID:              &id,
+		Owner:           &github.User{Login: &login},
+		Topics:          []string{"basketball", "hall-of-fame"},
+		License:         &github.License{SPDXID: &spdxID},
+		DefaultBranch:   &branch,
+		ForksCount:      &forks,
+		OpenIssuesCount: &openIssues,
+		Archived:        &archived,
+		PushedAt:        &github.Timestamp{Time: pushedAt},
+		CreatedAt:       &github.Timestamp{Time: createdAt},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, Topics{"basketball", "hall-of-fame"}, star.Topics)
+	assert.Equal(t, "MIT", *star.License)
+	assert.Equal(t, "celtics", *star.Owner)
+	assert.Equal(t, "main", *star.DefaultBranch)
+	assert.Equal(t, 12, star.Forks)
+	assert.Equal(t, 3, star.OpenIssues)
+	assert.True(t, star.Archived)
+	assert.False(t, star.Fork)
+	assert.Equal(t, pushedAt, *star.PushedAt)
+	assert.Equal(t, createdAt, *star.RemoteCreatedAt)
+}
+
+func TestNewStarFromGithubShouldFallBackToLicenseName(t *testing.T) {
+	id := int64(33)
+	spdxID := "NOASSERTION"
+	name := "Other"
+
+	star, err := NewStarFromGithub(nil, github.Repository{
+		ID:      &id,
+		License: &github.License{SPDXID: &spdxID, Name: &name},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Other", *star.License)
+}
+
+func TestNewStarFromGitlabShouldCopyMetadata(t *testing.T) {
+	lastActivityAt := time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2015, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	star, err := NewStarFromGitlab(gitlab.Project{
+		ID:                33,
+		TagList:           []string{"basketball"},
+		ForksCount:        12,
+		OpenIssuesCount:   3,
+		DefaultBranch:     "master",
+		Namespace:         &gitlab.ProjectNamespace{Path: "celtics"},
+		ForkedFromProject: &gitlab.ForkParent{},
+		LastActivityAt:    &lastActivityAt,
+		CreatedAt:         &createdAt,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, Topics{"basketball"}, star.Topics)
+	assert.Equal(t, "celtics", *star.Owner)
+	assert.Equal(t, "master", *star.DefaultBranch)
+	assert.Equal(t, 12, star.Forks)
+	assert.Equal(t, 3, star.OpenIssues)
+	assert.True(t, star.Fork)
+	assert.Equal(t, lastActivityAt, *star.PushedAt)
+	assert.Equal(t, createdAt, *star.RemoteCreatedAt)
+}
+
+func TestCreateOrUpdateStarShouldStoreTopics(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "1", Topics: Topics{"basketball", "hall-of-fame"}}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	found, err := FindStarByID(db, star.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, Topics{"basketball", "hall-of-fame"}, found.Topics)
+
+	star.Topics = nil
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	found, err = FindStarByID(db, star.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(found.Topics))
+}
+
+func TestHasTopicShouldIgnoreCase(t *testing.T) {
+	star := &Star{Topics: Topics{"Basketball"}}
+	assert.True(t, star.HasTopic("basketball"))
+	assert.False(t, star.HasTopic("baseball"))
+}
+
+func TestCreateOrUpdateStarShouldPreserveNoteRatingAndLocalPath(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "1", Stargazers: 10}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+	assert.Nil(t, star.SetNote(db, "# Great\nUse it"))
+	assert.Nil(t, star.SetRating(db, 4))
+	assert.Nil(t, star.SetLocalPath(db, "/src/star"))
+
+	update := &Star{RemoteID: "1", Stargazers: 20}
+	created, err := CreateOrUpdateStar(db, update, service)
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "# Great\nUse it", *update.Note)
+
+	found, err := FindStarByID(db, star.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, found.Stargazers)
+	assert.Equal(t, "# Great\nUse it", *found.Note)
+	assert.Equal(t, 4, found.Rating)
+	assert.Equal(t, "/src/star", *found.LocalPath)
+
+	assert.Nil(t, found.SetNote(db, "  "))
+	assert.NotNil(t, found.SetRating(db, 6))
+	found, err = FindStarByID(db, star.ID)
+	assert.Nil(t, err)
+	assert.Nil(t, found.Note)
+	assert.Equal(t, 4, found.Rating)
+}
+
+func TestFindRemoteIDsByServiceNameShouldSkipOtherServicesAndDeletedStars(t *testing.T) {
+	clearDB()
+
+	github, _, err := FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+	gitlab, _, err := FindOrCreateServiceByName(db, "gitlab")
+	assert.Nil(t, err)
+
+	for _, remoteID := range []string{"1", "2"} {
+		_, err = CreateOrUpdateStar(db, &Star{RemoteID: remoteID}, github)
+		assert.Nil(t, err)
+	}
+	_, err = CreateOrUpdateStar(db, &Star{RemoteID: "3"}, gitlab)
+	assert.Nil(t, err)
+
+	deleted, err := FindStarByRemoteIDAndService(db, "2", github)
+	assert.Nil(t, err)
+	assert.Nil(t, deleted.Delete(db))
+
+	remoteIDs, err := FindRemoteIDsByServiceName(db, "github")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1"}, remoteIDs)
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/jinzhu/gorm"
+)
+
+const otherName = "Other"
+const unknownName = "Unknown"
+
+// Count is a named count and its percentage of all stars
+type Count struct {
+	Name    string
+	Count   int
+	Percent float64
+}
+
+// Stats contains statistics about your stars
+type Stats struct {
+	Total            int
+	Untagged         int
+	UntaggedPercent  float64
+	MedianStargazers float64
+	Services         []Count
+	Languages        []Count
+	Tags             []Count
+	Years            []Count
+	Months           []Count
+}
+
+// FindStats gathers statistics about your stars, keeping the top languages and tags
+func FindStats(db *gorm.DB, top int) (*Stats, error) {
+	var stars []Star
+	db.Find(&stars)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	var services []Service
+	db.Find(&services)
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	serviceNames := make(map[uint]string)
+	for _, service := range services {
+		serviceNames[service.ID] = service.Name
+	}
+
+	stats := &Stats{
+		Total: len(stars),
+	}
+
+	byService := make(map[string]int)
+	byLanguage := make(map[string]int)
+	byYear := make(map[string]int)
+	byMonth := make(map[string]int)
+	stargazers := make([]int, 0, len(stars))
+
+	for _, star := range stars {
+		byService[serviceNames[star.ServiceID]]++
+
+		language := unknownName
+		if star.Language != nil && *star.Language != "" {
+			language = *star.Language
+		}
+		byLanguage[language]++
+
+		byYear[star.StarredAt.Format("2006")]++
+		byMonth[star.StarredAt.Format("2006-01")]++
+		stargazers = append(stargazers, star.Stargazers)
+	}
+
+	stats.Services = sortByCount(byService, stats.Total)
+	stats.Languages = keepTop(sortByCount(byLanguage, stats.Total), top, stats.Total)
+	stats.Years = sortByName(byYear, stats.Total)
+	stats.Months = sortByName(byMonth, stats.Total)
+	stats.MedianStargazers = median(stargazers)
+
+	tags, err := FindTagsWithStarCount(db)
+	if err != nil {
+		return nil, err
+	}
+	byTag := make(map[string]int)
+	for _, tag := range tags {
+		if tag.StarCount > 0 {
+			byTag[tag.Name] = tag.StarCount
+		}
+	}
+	stats.Tags = sortByCount(byTag, stats.Total)
+	if top > 0 && len(stats.Tags) > top {
+		stats.Tags = stats.Tags[:top]
+	}
+
+	untagged, err := FindUntaggedStars(db, "")
+	if err != nil {
+		return nil, err
+	}
+	stats.Untagged = len(untagged)
+	stats.UntaggedPercent = percent(stats.Untagged, stats.Total)
+
+	return stats, nil
+}
+
+func sortByCount(counts map[string]int, total int) []Count {
+	sorted := toCounts(counts, total)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Count == sorted[j].Count {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Count > sorted[j].Count
+	})
+	return sorted
+}
+
+func sortByName(counts map[string]int, total int) []Count {
+	sorted := toCounts(counts, total)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+func toCounts(counts map[string]int, total int) []Count {
+	values := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		values = append(values, Count{
+			Name:    name,
+			Count:   count,
+			Percent: percent(count, total),
+		})
+	}
+	return values
+}
+
+func keepTop(counts []Count, top int, total int) []Count {
+	if top <= 0 || len(counts) <= top {
+		return counts
+	}
+
+	other := 0
+	for _, count := range counts[top:] {
+		other += count.Count
+	}
+	return append(counts[:top], Count{
+		Name:    otherName,
+		Count:   other,
+		Percent: percent(other, total),
+	})
+}
+
+func percent(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(total)
+}
+
+func median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Ints(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return float64(values[middle-1]+values[middle]) / 2
+	}
+	return float64(values[middle])
+}
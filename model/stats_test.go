@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindStatsShouldBeEmptyWhenNoStars(t *testing.T) {
+	clearDB()
+
+	stats, err := FindStats(db, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, stats.Total)
+	assert.Equal(t, 0.0, stats.UntaggedPercent)
+	assert.Equal(t, 0.0, stats.MedianStargazers)
+	assert.Equal(t, 0, len(stats.Languages))
+}
+
+func TestFindStatsShouldCountStars(t *testing.T) {
+	clearDB()
+
+	github, _, err := FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+	gitlab, _, err := FindOrCreateServiceByName(db, "gitlab")
+	assert.Nil(t, err)
+
+	golang := "Go"
+	rust := "Rust"
+	for i, star := range []*Star{
+		{RemoteID: "1", Language: &golang, Stargazers: 10, StarredAt: time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{RemoteID: "2", Language: &golang, Stargazers: 20, StarredAt: time.Date(2016, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{RemoteID: "3", Language: &rust, Stargazers: 30, StarredAt: time.Date(2017, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{RemoteID: "4", Stargazers: 40, StarredAt: time.Date(2017, time.July, 2, 0, 0, 0, 0, time.UTC)},
+	} {
+		service := github
+		if i == 3 {
+			service = gitlab
+		}
+		_, err = CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+
+		if i == 0 {
+			tag, _, err := FindOrCreateTagByName(db, "cli")
+			assert.Nil(t, err)
+			assert.Nil(t, star.AddTag(db, tag))
+		}
+	}
+
+	stats, err := FindStats(db, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, stats.Total)
+	assert.Equal(t, 3, stats.Untagged)
+	assert.Equal(t, 75.0, stats.UntaggedPercent)
+	assert.Equal(t, 25.0, stats.MedianStargazers)
+
+	assert.Equal(t, []Count{{"github", 3, 75}, {"gitlab", 1, 25}}, stats.Services)
+	assert.Equal(t, []Count{{"Go", 2, 50}, {"Other", 2, 50}}, stats.Languages)
+	assert.Equal(t, []Count{{"cli", 1, 25}}, stats.Tags)
+	assert.Equal(t, []Count{{"2016", 2, 50}, {"2017", 2, 50}}, stats.Years)
+	assert.Equal(t, "2016-06", stats.Months[0].Name)
+	assert.Equal(t, 2, stats.Months[2].Count)
+}
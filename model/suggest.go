@@ -0,0 +1,133 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/blevesearch/bleve"
+	"github.com/jinzhu/gorm"
+)
+
+// maxCandidates is how many similar stars to look through for tagged ones
+const maxCandidates = 100
+
+// maxSuggestions is how many tags to suggest for a star
+const maxSuggestions = 5
+
+// Suggestion is a tag suggested for a star, with a confidence from 0 to 1
+type Suggestion struct {
+	Tag        string
+	Confidence float64
+}
+
+// Suggester suggests tags for stars from the tags on the most similar tagged stars
+type Suggester struct {
+	Neighbors  int
+	index      bleve.Index
+	tagsByStar map[string][]string
+}
+
+// NewSuggester creates a suggester, loading which stars have which tags
+func NewSuggester(db *gorm.DB, index bleve.Index, neighbors int) (*Suggester, error) {
+	if neighbors < 1 {
+		return nil, errors.New("you must consider at least one neighbor")
+	}
+
+	rows, err := db.Raw(`
+		SELECT ST.STAR_ID, T.NAME
+		FROM STAR_TAGS ST
+		INNER JOIN TAGS T ON T.ID = ST.TAG_ID
+		WHERE T.DELETED_AT IS NULL
+		ORDER BY T.NAME`).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tagsByStar := make(map[string][]string)
+	for rows.Next() {
+		var starID uint
+		var name string
+		if err := rows.Scan(&starID, &name); err != nil {
+			return nil, err
+		}
+		ID := fmt.Sprintf("%d", starID)
+		tagsByStar[ID] = append(tagsByStar[ID], name)
+	}
+
+	return &Suggester{
+		Neighbors:  neighbors,
+		index:      index,
+		tagsByStar: tagsByStar,
+	}, rows.Err()
+}
+
+// Suggest suggests tags for a star, most confident first. Confidence is the share of the
+// neighbors' search scores that comes from neighbors with the tag
+func (s *Suggester) Suggest(star *Star) ([]Suggestion, error) {
+	var queries []bleve.Query
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"Description", star.Description},
+		{"Name", star.Name},
+		{"Language", star.Language},
+	} {
+		if field.value != nil && *field.value != "" {
+			queries = append(queries, bleve.NewMatchQuery(*field.value).SetField(field.name))
+		}
+	}
+	if len(queries) == 0 {
+		return nil, nil
+	}
+
+	request := bleve.NewSearchRequestOptions(bleve.NewDisjunctionQuery(queries), maxCandidates, 0, false)
+	results, err := s.index.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	self := fmt.Sprintf("%d", star.ID)
+	scores := make(map[string]float64)
+	total := 0.0
+	neighbors := 0
+	for _, hit := range results.Hits {
+		tags, ok := s.tagsByStar[hit.ID]
+		if hit.ID == self || !ok {
+			continue
+		}
+		for _, tag := range tags {
+			scores[tag] += hit.Score
+		}
+		total += hit.Score
+		if neighbors++; neighbors >= s.Neighbors {
+			break
+		}
+	}
+
+	suggestions := make([]Suggestion, 0, len(scores))
+	for tag, score := range scores {
+		suggestions = append(suggestions, Suggestion{
+			Tag:        tag,
+			Confidence: score / total,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence == suggestions[j].Confidence {
+			return suggestions[i].Tag < suggestions[j].Tag
+		}
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions, nil
+}
+
+// Learn records tags added to a star, so later suggestions can use them
+func (s *Suggester) Learn(star *Star, tags ...string) {
+	ID := fmt.Sprintf("%d", star.ID)
+	s.tagsByStar[ID] = append(s.tagsByStar[ID], tags...)
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestShouldSuggestTagsFromSimilarStars(t *testing.T) {
+	rmIndex()
+	defer rmIndex()
+	clearDB()
+
+	index, err := InitIndex(indexPath)
+	assert.Nil(t, err)
+	defer index.Close()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	goLang, vim := "Go", "VimL"
+	var stars []*Star
+	for i, s := range []struct {
+		description string
+		language    *string
+		tags        []string
+	}{
+		{"A terminal text editor", &goLang, []string{"editor", "cli"}},
+		{"A modal text editor for the terminal", &vim, []string{"editor"}},
+		{"A web framework", &goLang, []string{"web"}},
+		{"A tiny terminal text editor", &goLang, nil},
+		{"Nothing alike", nil, nil},
+	} {
+		description := s.description
+		star := &Star{
+			RemoteID:    fmt.Sprintf("%d", i),
+			Description: &description,
+			Language:    s.language,
+		}
+		_, err = CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+		for _, name := range s.tags {
+			tag, _, err := FindOrCreateTagByName(db, name)
+			assert.Nil(t, err)
+			assert.Nil(t, star.AddTag(db, tag))
+		}
+		assert.Nil(t, star.Index(index, db))
+		stars = append(stars, star)
+	}
+
+	suggester, err := NewSuggester(db, index, 10)
+	assert.Nil(t, err)
+
+	suggestions, err := suggester.Suggest(stars[3])
+	assert.Nil(t, err)
+	assert.True(t, len(suggestions) >= 2)
+	assert.Equal(t, "editor", suggestions[0].Tag)
+	assert.True(t, suggestions[0].Confidence > 0.5)
+	assert.True(t, suggestions[0].Confidence <= 1)
+	for _, suggestion := range suggestions[1:] {
+		assert.True(t, suggestion.Confidence <= suggestions[0].Confidence)
+	}
+
+	suggestions, err = suggester.Suggest(&Star{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(suggestions))
+}
+
+func TestSuggestShouldLimitNeighbors(t *testing.T) {
+	rmIndex()
+	defer rmIndex()
+	clearDB()
+
+	index, err := InitIndex(indexPath)
+	assert.Nil(t, err)
+	defer index.Close()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	for i, s := range []struct {
+		description string
+		tag         string
+	}{
+		{"text editor text editor", "editor"},
+		{"editor plugin", "plugin"},
+	} {
+		description := s.description
+		star := &Star{RemoteID: fmt.Sprintf("%d", i), Description: &description}
+		_, err = CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+		tag, _, err := FindOrCreateTagByName(db, s.tag)
+		assert.Nil(t, err)
+		assert.Nil(t, star.AddTag(db, tag))
+		assert.Nil(t, star.Index(index, db))
+	}
+
+	suggester, err := NewSuggester(db, index, 1)
+	assert.Nil(t, err)
+
+	description := "text editor"
+	suggestions, err := suggester.Suggest(&Star{Description: &description})
+	assert.Nil(t, err)
+	assert.Equal(t, []Suggestion{{Tag: "editor", Confidence: 1}}, suggestions)
+}
+
+func TestNewSuggesterShouldRequireANeighbor(t *testing.T) {
+	for _, neighbors := range []int{0, -1} {
+		_, err := NewSuggester(db, nil, neighbors)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestLearnShouldAddTags(t *testing.T) {
+	suggester := &Suggester{tagsByStar: make(map[string][]string)}
+	star := &Star{}
+	star.ID = 7
+	suggester.Learn(star, "cli")
+	assert.Equal(t, []string{"cli"}, suggester.tagsByStar["7"])
+}
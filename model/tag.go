@@ -1,19 +1,32 @@
 package model
 
 import (
-	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 
 	"github.com/jinzhu/gorm"
 )
 
-// Tag represents a tag in the database
+// TagSeparator separates the levels of a hierarchical tag name, like lang/go/web
+const TagSeparator = "/"
+
+// InvalidError reports a change to tags that can't be made as asked, like an empty or duplicate name
+type InvalidError string
+
+func (e InvalidError) Error() string {
+	return string(e)
+}
+
+// Tag represents a tag in the database. A tag's name is its full path, and
+// ParentID points to the tag one level up
 type Tag struct {
 	gorm.Model
 	Name      string
+	ParentID  *uint
 	StarCount int    `gorm:"-"`
+	Depth     int    `gorm:"-"`
 	Stars     []Star `gorm:"many2many:star_tags;"`
 }
 
@@ -31,19 +44,21 @@ func FindTags(db *gorm.DB) ([]Tag, error) {
 	return tags, db.Error
 }
 
-// FindTagsWithStarCount finds all tags and gets their count of stars
+// FindTagsWithStarCount finds all tags in tree order, with each tag's count of
+// stars rolled up from its descendants and its depth in the tree
 func FindTagsWithStarCount(db *gorm.DB) ([]Tag, error) {
-	var tags []Tag
-	rows, err := db.Raw(`
-		SELECT T.NAME, COUNT(ST.TAG_ID) AS STARCOUNT
-		FROM TAGS T
-		LEFT JOIN STAR_TAGS ST ON T.ID = ST.TAG_ID
-		WHERE T.DELETED_AT IS NULL
-		GROUP BY T.ID
-		ORDER BY T.NAME`).Rows()
+	tags, err := FindTags(db)
+	if err != nil {
+		return nil, err
+	}
 
+	rows, err := db.Raw(`
+		SELECT ST.TAG_ID, ST.STAR_ID
+		FROM STAR_TAGS ST
+		INNER JOIN STARS S ON S.ID = ST.STAR_ID
+		WHERE S.DELETED_AT IS NULL`).Rows()
 	if err != nil {
-		return tags, err
+		return nil, err
 	}
 
 	defer func() {
@@ -53,37 +68,188 @@ func FindTagsWithStarCount(db *gorm.DB) ([]Tag, error) {
 		}
 	}()
 
+	starsByTag := make(map[uint]map[uint]bool)
 	for rows.Next() {
-		var tag Tag
-		if err = rows.Scan(&tag.Name, &tag.StarCount); err != nil {
-			return tags, err
+		var tagID, starID uint
+		if err = rows.Scan(&tagID, &starID); err != nil {
+			return nil, err
+		}
+		if starsByTag[tagID] == nil {
+			starsByTag[tagID] = make(map[uint]bool)
 		}
-		tags = append(tags, tag)
+		starsByTag[tagID][starID] = true
 	}
-	return tags, db.Error
+
+	children, roots := tagTree(tags)
+	ordered := make([]Tag, 0, len(tags))
+
+	// Walk the tree depth first, collecting each subtree's distinct stars
+	var walk func(tag Tag, depth int) map[uint]bool
+	walk = func(tag Tag, depth int) map[uint]bool {
+		tag.Depth = depth
+		position := len(ordered)
+		ordered = append(ordered, tag)
+
+		stars := make(map[uint]bool)
+		for starID := range starsByTag[tag.ID] {
+			stars[starID] = true
+		}
+		for _, child := range children[tag.ID] {
+			for starID := range walk(child, depth+1) {
+				stars[starID] = true
+			}
+		}
+		ordered[position].StarCount = len(stars)
+		return stars
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return ordered, db.Error
+}
+
+// tagTree groups tags under their parents, sorted by name. Tags whose parent
+// is missing are treated as roots
+func tagTree(tags []Tag) (map[uint][]Tag, []Tag) {
+	ids := make(map[uint]bool)
+	for _, tag := range tags {
+		ids[tag.ID] = true
+	}
+
+	children := make(map[uint][]Tag)
+	var roots []Tag
+	for _, tag := range tags {
+		if tag.ParentID != nil && ids[*tag.ParentID] {
+			children[*tag.ParentID] = append(children[*tag.ParentID], tag)
+		} else {
+			roots = append(roots, tag)
+		}
+	}
+
+	byName := func(tags []Tag) {
+		sort.SliceStable(tags, func(i, j int) bool {
+			return tags[i].Name < tags[j].Name
+		})
+	}
+	byName(roots)
+	for _, siblings := range children {
+		byName(siblings)
+	}
+	return children, roots
+}
+
+// FindTagByID finds a tag by ID
+func FindTagByID(db *gorm.DB, ID uint) (*Tag, error) {
+	var tag Tag
+	if db.First(&tag, ID).RecordNotFound() {
+		return nil, fmt.Errorf("tag '%d' not found", ID)
+	}
+	return &tag, db.Error
 }
 
-// FindTagByName finds a tag by name
+// FindTagByName finds a tag by name, or by one of its aliases
 func FindTagByName(db *gorm.DB, name string) (*Tag, error) {
 	var tag Tag
 	if db.Where("lower(name) = ?", strings.ToLower(name)).First(&tag).RecordNotFound() {
-		return nil, db.Error
+		return findTagByAlias(db, name)
 	}
 	return &tag, db.Error
 }
 
-// FindOrCreateTagByName finds a tag by name, creating if it doesn't exist
+// FindOrCreateTagByName finds a tag by name or alias, creating it and any missing ancestors if it doesn't exist
 func FindOrCreateTagByName(db *gorm.DB, name string) (*Tag, bool, error) {
-	var tag Tag
-	if db.Where("lower(name) = ?", strings.ToLower(name)).First(&tag).RecordNotFound() {
-		tag.Name = name
-		err := db.Create(&tag).Error
-		return &tag, true, err
+	name = CleanTagName(name)
+	if name == "" {
+		return nil, false, InvalidError("you must specify a name")
+	}
+	if IsSavedSearchName(name) {
+		return nil, false, InvalidError(fmt.Sprintf("tag names can't start with '%s'", SavedSearchPrefix))
+	}
+
+	existing, err := FindTagByName(db, name)
+	if err != nil || existing != nil {
+		return existing, false, err
+	}
+
+	tag := Tag{Name: name}
+	if parentName := parentTagName(name); parentName != "" {
+		// The parent may be an alias, so build the name from the parent's real name
+		parent, _, err := FindOrCreateTagByName(db, parentName)
+		if err != nil {
+			return nil, false, err
+		}
+		tag.Name = parent.Name + TagSeparator + tag.Leaf()
+		tag.ParentID = &parent.ID
+	}
+	err = db.Create(&tag).Error
+	return &tag, true, err
+}
+
+// CleanTagName trims spaces and separators from each level of a tag name and drops empty levels
+func CleanTagName(name string) string {
+	var levels []string
+	for _, level := range strings.Split(name, TagSeparator) {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+	return strings.Join(levels, TagSeparator)
+}
+
+// parentTagName returns the name of a tag's parent, or empty for a top-level tag
+func parentTagName(name string) string {
+	if n := strings.LastIndex(name, TagSeparator); n > 0 {
+		return name[:n]
+	}
+	return ""
+}
+
+// Leaf returns the last level of a tag's name
+func (tag *Tag) Leaf() string {
+	return tag.Name[strings.LastIndex(tag.Name, TagSeparator)+1:]
+}
+
+// FindDescendants finds a tag's children, their children, and so on
+func (tag *Tag) FindDescendants(db *gorm.DB) ([]Tag, error) {
+	var descendants []Tag
+	parents := []uint{tag.ID}
+	for len(parents) > 0 {
+		var children []Tag
+		if err := db.Where("parent_id IN (?)", parents).Order("name").Find(&children).Error; err != nil {
+			return nil, err
+		}
+		parents = nil
+		for _, child := range children {
+			parents = append(parents, child.ID)
+		}
+		descendants = append(descendants, children...)
+	}
+	return descendants, nil
+}
+
+// subtreeIDs returns the IDs of a tag and all its descendants
+func (tag *Tag) subtreeIDs(db *gorm.DB) ([]uint, error) {
+	descendants, err := tag.FindDescendants(db)
+	if err != nil {
+		return nil, err
+	}
+	IDs := []uint{tag.ID}
+	for _, descendant := range descendants {
+		IDs = append(IDs, descendant.ID)
+	}
+	return IDs, nil
+}
+
+// findTagSubtreeIDs returns the IDs of the named tag and its descendants, or none if the tag doesn't exist
+func findTagSubtreeIDs(db *gorm.DB, name string) ([]uint, error) {
+	tag, err := FindTagByName(db, name)
+	if err != nil || tag == nil {
+		return nil, err
 	}
-	return &tag, false, nil
+	return tag.subtreeIDs(db)
 }
 
-// LoadStars loads the stars for a tag
+// LoadStars loads the stars for a tag, including the stars tagged with its descendants
 func (tag *Tag) LoadStars(db *gorm.DB, match string) error {
 	// Make sure tag exists in database, or we will panic
 	var existing Tag
@@ -91,50 +257,210 @@ func (tag *Tag) LoadStars(db *gorm.DB, match string) error {
 		return fmt.Errorf("tag '%d' not found", tag.ID)
 	}
 
-	if match != "" {
-		var stars []Star
-		db.Raw(`
-			SELECT *
-			FROM STARS S
-			INNER JOIN STAR_TAGS ST ON S.ID = ST.STAR_ID
-			WHERE S.DELETED_AT IS NULL
-			AND ST.TAG_ID = ?
-			AND LOWER(S.FULL_NAME) LIKE ?
-			ORDER BY S.FULL_NAME`,
-			tag.ID,
-			fmt.Sprintf("%%%s%%", strings.ToLower(match))).Scan(&stars)
-		tag.Stars = stars
-		return db.Error
+	IDs, err := tag.subtreeIDs(db)
+	if err != nil {
+		return err
 	}
-	return db.Model(tag).Association("Stars").Find(&tag.Stars).Error
+
+	var stars []Star
+	matchingName(db, match).Where("id IN (SELECT star_id FROM star_tags WHERE tag_id IN (?))", IDs).
+		Order("full_name").Find(&stars)
+	tag.Stars = stars
+	return db.Error
 }
 
-// Rename renames a tag -- new name must not already exist
+// Rename renames a tag -- new name must not already exist. Renaming a tag moves
+// its descendants along with it, so renaming lang/go to go renames lang/go/web to go/web.
+// If any of it fails, none of the tags change
 func (tag *Tag) Rename(db *gorm.DB, name string) error {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	original := *tag
+	if err := tag.rename(tx, name); err != nil {
+		tx.Rollback()
+		*tag = original
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func (tag *Tag) rename(db *gorm.DB, name string) error {
+	name = CleanTagName(name)
+	if name == "" {
+		return InvalidError("you must specify a name")
+	}
+
+	if IsSavedSearchName(name) {
+		return InvalidError(fmt.Sprintf("tag names can't start with '%s'", SavedSearchPrefix))
+	}
+
 	// Can't rename to the same name
 	if name == tag.Name {
-		return errors.New("you can't rename to the same name")
+		return InvalidError("you can't rename to the same name")
+	}
+
+	oldPrefix := strings.ToLower(tag.Name + TagSeparator)
+	if strings.HasPrefix(strings.ToLower(name), oldPrefix) {
+		return InvalidError(fmt.Sprintf("you can't move '%s' under itself", tag.Name))
+	}
+
+	descendants, err := tag.FindDescendants(db)
+	if err != nil {
+		return err
 	}
 
 	// If they're just changing case, allow. Otherwise, block the change
 	if !strings.EqualFold(name, tag.Name) {
-		existing, err := FindTagByName(db, name)
+		for _, candidate := range append([]Tag{*tag}, descendants...) {
+			newName := name + candidate.Name[len(tag.Name):]
+			existing, err := FindTagByName(db, newName)
+			if err != nil {
+				return err
+			}
+			if existing != nil && existing.ID != candidate.ID {
+				return InvalidError(fmt.Sprintf("tag '%s' already exists", existing.Name))
+			}
+		}
+	}
+
+	tag.ParentID = nil
+	if parentName := parentTagName(name); parentName != "" {
+		parent, _, err := FindOrCreateTagByName(db, parentName)
 		if err != nil {
 			return err
 		}
-		if existing != nil {
-			return fmt.Errorf("tag '%s' already exists", existing.Name)
+		tag.ParentID = &parent.ID
+	}
+
+	for _, descendant := range descendants {
+		descendant.Name = name + descendant.Name[len(tag.Name):]
+		if err := db.Save(&descendant).Error; err != nil {
+			return err
 		}
 	}
 
+	// A tag renamed to one of its aliases no longer needs the alias
+	if err := removeAliases(db, name); err != nil {
+		return err
+	}
+
 	tag.Name = name
 	return db.Save(tag).Error
 }
 
-// Delete deletes a tag and disassociates it from any stars
+// Delete deletes a tag and disassociates it from any stars, saving a snapshot so restoring
+// the tag from the trash can put them back. Tags with children can't be deleted
 func (tag *Tag) Delete(db *gorm.DB) error {
+	var children int
+	if err := db.Model(&Tag{}).Where("parent_id = ?", tag.ID).Count(&children).Error; err != nil {
+		return err
+	}
+	if children > 0 {
+		return InvalidError(fmt.Sprintf("tag '%s' has child tags", tag.Name))
+	}
+
+	if err := tag.snapshot(db); err != nil {
+		return err
+	}
 	if err := db.Model(tag).Association("Stars").Clear().Error; err != nil {
 		return err
 	}
+	if err := db.Where("tag_id = ?", tag.ID).Delete(&TagAlias{}).Error; err != nil {
+		return err
+	}
 	return db.Delete(tag).Error
 }
+
+// Merge moves the stars and aliases of the specified tags to this tag, then deletes
+// them, keeping their names as aliases. It returns the IDs of the stars whose tags changed.
+// If any of it fails, none of the tags change
+func (tag *Tag) Merge(db *gorm.DB, others []*Tag) ([]uint, error) {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	starIDs, err := tag.merge(tx, uniqueTags(others))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return starIDs, tx.Commit().Error
+}
+
+// uniqueTags drops repeats of the same tag, keeping the first of each
+func uniqueTags(tags []*Tag) []*Tag {
+	var unique []*Tag
+	seen := make(map[uint]bool)
+	for _, tag := range tags {
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
+
+func (tag *Tag) merge(db *gorm.DB, others []*Tag) ([]uint, error) {
+	var starIDs []uint
+	seen := make(map[uint]bool)
+	for _, other := range others {
+		if other.ID == tag.ID {
+			return starIDs, InvalidError(fmt.Sprintf("you can't merge tag '%s' into itself", tag.Name))
+		}
+
+		var children int
+		if err := db.Model(&Tag{}).Where("parent_id = ?", other.ID).Count(&children).Error; err != nil {
+			return starIDs, err
+		}
+		if children > 0 {
+			return starIDs, InvalidError(fmt.Sprintf("tag '%s' has child tags", other.Name))
+		}
+
+		var starTags []StarTag
+		if err := db.Where("tag_id = ?", other.ID).Find(&starTags).Error; err != nil {
+			return starIDs, err
+		}
+		for _, starTag := range starTags {
+			if !seen[starTag.StarID] {
+				seen[starTag.StarID] = true
+				starIDs = append(starIDs, starTag.StarID)
+			}
+		}
+
+		for _, statement := range []struct {
+			sql  string
+			args []interface{}
+		}{
+			// Drop associations the stars already have with this tag, then move the rest
+			{`DELETE FROM STAR_TAGS
+				WHERE TAG_ID = ?
+				AND STAR_ID IN (SELECT STAR_ID FROM STAR_TAGS WHERE TAG_ID = ?)`, []interface{}{other.ID, tag.ID}},
+			{`UPDATE STAR_TAGS SET TAG_ID = ? WHERE TAG_ID = ?`, []interface{}{tag.ID, other.ID}},
+			// Keep list mappings only for services this tag doesn't have a list on yet
+			{`DELETE FROM LIST_MAPPINGS
+				WHERE TAG_ID = ?
+				AND SERVICE_ID IN (SELECT SERVICE_ID FROM LIST_MAPPINGS WHERE TAG_ID = ? AND DELETED_AT IS NULL)`, []interface{}{other.ID, tag.ID}},
+			{`UPDATE LIST_MAPPINGS SET TAG_ID = ? WHERE TAG_ID = ?`, []interface{}{tag.ID, other.ID}},
+			{`UPDATE TAG_ALIASES SET TAG_ID = ? WHERE TAG_ID = ?`, []interface{}{tag.ID, other.ID}},
+		} {
+			if err := db.Exec(statement.sql, statement.args...).Error; err != nil {
+				return starIDs, err
+			}
+		}
+
+		if err := db.Delete(other).Error; err != nil {
+			return starIDs, err
+		}
+		if err := db.Create(&TagAlias{
+			Name:  other.Name,
+			TagID: tag.ID,
+		}).Error; err != nil {
+			return starIDs, err
+		}
+	}
+	return starIDs, nil
+}
@@ -1,8 +1,11 @@
 package model
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -357,3 +360,308 @@ func TestLoadStarsShouldFillStarsWithMatch(t *testing.T) {
 	assert.Equal(t, "Jacksonville Jaguars", *tag.Stars[0].FullName)
 	assert.Equal(t, "Jacksonville Suns", *tag.Stars[1].FullName)
 }
+
+func TestFindOrCreateTagByNameShouldCreateAncestors(t *testing.T) {
+	clearDB()
+
+	tag, created, err := FindOrCreateTagByName(db, " lang/ go//web ")
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "lang/go/web", tag.Name)
+	assert.Equal(t, "web", tag.Leaf())
+
+	parent, err := FindTagByName(db, "lang/go")
+	assert.Nil(t, err)
+	assert.NotNil(t, parent)
+	assert.Equal(t, parent.ID, *tag.ParentID)
+
+	root, err := FindTagByName(db, "lang")
+	assert.Nil(t, err)
+	assert.NotNil(t, root)
+	assert.Equal(t, root.ID, *parent.ParentID)
+	assert.Nil(t, root.ParentID)
+
+	descendants, err := root.FindDescendants(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(descendants))
+}
+
+func TestLoadStarsShouldIncludeDescendants(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	tags := make(map[string]*Tag)
+	for i, name := range []string{"lang", "lang/go", "lang/go/web", "lang/rust"} {
+		tags[name], _, err = FindOrCreateTagByName(db, name)
+		assert.Nil(t, err)
+
+		fullName := fmt.Sprintf("star%d", i)
+		star := &Star{RemoteID: fullName, FullName: &fullName}
+		_, err = CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+		assert.Nil(t, star.AddTag(db, tags[name]))
+	}
+
+	assert.Nil(t, tags["lang/go"].LoadStars(db, ""))
+	assert.Equal(t, 2, len(tags["lang/go"].Stars))
+
+	assert.Nil(t, tags["lang"].LoadStars(db, "star3"))
+	assert.Equal(t, 1, len(tags["lang"].Stars))
+
+	count, err := CountStarsByLanguageAndTag(db, "", "lang")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count)
+
+	stars, err := FindStarsByLanguageAndOrTag(db, "", "", "lang/go", true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stars))
+}
+
+func TestFindTagsWithStarCountShouldRollUpCounts(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	name := "star"
+	star := &Star{RemoteID: "1", FullName: &name}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	for _, name := range []string{"lang/go/web", "lang/go", "lang/rust"} {
+		tag, _, err := FindOrCreateTagByName(db, name)
+		assert.Nil(t, err)
+		assert.Nil(t, star.AddTag(db, tag))
+	}
+	_, _, err = FindOrCreateTagByName(db, "cli")
+	assert.Nil(t, err)
+
+	tags, err := FindTagsWithStarCount(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(tags))
+
+	var lines []string
+	for _, tag := range tags {
+		lines = append(lines, fmt.Sprintf("%d %s %d", tag.Depth, tag.Name, tag.StarCount))
+	}
+	assert.Equal(t, []string{
+		"0 cli 0",
+		"0 lang 1",
+		"1 lang/go 1",
+		"2 lang/go/web 1",
+		"1 lang/rust 1",
+	}, lines)
+}
+
+func TestRenameTagShouldMoveSubtree(t *testing.T) {
+	clearDB()
+
+	_, _, err := FindOrCreateTagByName(db, "lang/go/web")
+	assert.Nil(t, err)
+	tag, err := FindTagByName(db, "lang/go")
+	assert.Nil(t, err)
+
+	assert.Nil(t, tag.Rename(db, "code/golang"))
+	assert.Equal(t, "code/golang", tag.Name)
+
+	parent, err := FindTagByName(db, "code")
+	assert.Nil(t, err)
+	assert.NotNil(t, parent)
+	assert.Equal(t, parent.ID, *tag.ParentID)
+
+	web, err := FindTagByName(db, "code/golang/web")
+	assert.Nil(t, err)
+	assert.NotNil(t, web)
+	assert.Equal(t, tag.ID, *web.ParentID)
+
+	old, err := FindTagByName(db, "lang/go/web")
+	assert.Nil(t, err)
+	assert.Nil(t, old)
+
+	assert.Nil(t, tag.Rename(db, "golang"))
+	assert.Nil(t, tag.ParentID)
+}
+
+func TestRenameTagShouldChangeNothingWhenAnyTagFails(t *testing.T) {
+	clearDB()
+
+	_, _, err := FindOrCreateTagByName(db, "lang/go/web")
+	assert.Nil(t, err)
+	tag, err := FindTagByName(db, "lang/go")
+	assert.Nil(t, err)
+
+	// Fail saving the renamed tag itself, after its parent and descendants have changed
+	db.Callback().Update().Before("gorm:update").Register("limo:fail_rename", func(scope *gorm.Scope) {
+		if renamed, ok := scope.Value.(*Tag); ok && renamed.Name == "code/golang" {
+			_ = scope.Err(errors.New("failed"))
+		}
+	})
+	defer db.Callback().Update().Remove("limo:fail_rename")
+
+	assert.NotNil(t, tag.Rename(db, "code/golang"))
+	assert.Equal(t, "lang/go", tag.Name)
+
+	for name, exists := range map[string]bool{
+		"lang/go/web":     true,
+		"code":            false,
+		"code/golang/web": false,
+	} {
+		found, err := FindTagByName(db, name)
+		assert.Nil(t, err)
+		assert.Equal(t, exists, found != nil, name)
+	}
+}
+
+func TestRenameTagShouldRejectMovesUnderItselfAndCollisions(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "lang/go/web")
+	assert.Nil(t, err)
+	_, _, err = FindOrCreateTagByName(db, "go/web")
+	assert.Nil(t, err)
+
+	goTag, err := FindTagByName(db, "lang/go")
+	assert.Nil(t, err)
+
+	err = goTag.Rename(db, "lang/go/web/x")
+	assert.NotNil(t, err)
+	assert.Equal(t, "you can't move 'lang/go' under itself", err.Error())
+
+	err = goTag.Rename(db, "go2")
+	assert.Nil(t, err)
+
+	err = tag.Rename(db, "go/web")
+	assert.NotNil(t, err)
+	assert.Equal(t, "tag 'go/web' already exists", err.Error())
+}
+
+func TestDeleteTagShouldRefuseTagWithChildren(t *testing.T) {
+	clearDB()
+
+	_, _, err := FindOrCreateTagByName(db, "lang/go")
+	assert.Nil(t, err)
+	tag, err := FindTagByName(db, "lang")
+	assert.Nil(t, err)
+
+	err = tag.Delete(db)
+	assert.NotNil(t, err)
+	assert.Equal(t, "tag 'lang' has child tags", err.Error())
+}
+
+func TestMergeTagsShouldMoveStarsAndKeepAliases(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	into, _, err := FindOrCreateTagByName(db, "javascript")
+	assert.Nil(t, err)
+	js, _, err := FindOrCreateTagByName(db, "js")
+	assert.Nil(t, err)
+	libs, _, err := FindOrCreateTagByName(db, "JavaScript-libs")
+	assert.Nil(t, err)
+	assert.Nil(t, libs.AddAlias(db, "jslibs"))
+
+	var stars []*Star
+	for i, tags := range [][]*Tag{{into, js}, {js}, {libs}, {}} {
+		star := &Star{RemoteID: fmt.Sprintf("%d", i)}
+		_, err = CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+		for _, tag := range tags {
+			assert.Nil(t, star.AddTag(db, tag))
+		}
+		stars = append(stars, star)
+	}
+
+	starIDs, err := into.Merge(db, []*Tag{js, libs})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{stars[0].ID, stars[1].ID, stars[2].ID}, starIDs)
+
+	assert.Nil(t, into.LoadStars(db, ""))
+	assert.Equal(t, 3, len(into.Stars))
+
+	var starTags int
+	db.Model(&StarTag{}).Count(&starTags)
+	assert.Equal(t, 3, starTags)
+
+	for _, name := range []string{"js", "javascript-libs", "jslibs"} {
+		tag, err := FindTagByName(db, name)
+		assert.Nil(t, err)
+		assert.NotNil(t, tag, name)
+		assert.Equal(t, into.ID, tag.ID, name)
+	}
+
+	tags, err := FindTags(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(tags))
+}
+
+func TestMergeTagsShouldIgnoreRepeatedTags(t *testing.T) {
+	clearDB()
+
+	into, _, err := FindOrCreateTagByName(db, "javascript")
+	assert.Nil(t, err)
+	js, _, err := FindOrCreateTagByName(db, "js")
+	assert.Nil(t, err)
+
+	_, err = into.Merge(db, []*Tag{js, js})
+	assert.Nil(t, err)
+
+	aliases, err := into.FindAliases(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(aliases))
+	assert.Equal(t, "js", aliases[0].Name)
+}
+
+func TestMergeTagsShouldRejectItselfAndParents(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "lang/go")
+	assert.Nil(t, err)
+	parent, err := FindTagByName(db, "lang")
+	assert.Nil(t, err)
+
+	_, err = tag.Merge(db, []*Tag{tag})
+	assert.NotNil(t, err)
+	assert.Equal(t, "you can't merge tag 'lang/go' into itself", err.Error())
+
+	_, err = tag.Merge(db, []*Tag{parent})
+	assert.NotNil(t, err)
+	assert.Equal(t, "tag 'lang' has child tags", err.Error())
+}
+
+func TestMergeTagsShouldChangeNothingWhenAnyTagFails(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	into, _, err := FindOrCreateTagByName(db, "javascript")
+	assert.Nil(t, err)
+	js, _, err := FindOrCreateTagByName(db, "js")
+	assert.Nil(t, err)
+	_, _, err = FindOrCreateTagByName(db, "lang/go")
+	assert.Nil(t, err)
+	parent, err := FindTagByName(db, "lang")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "1"}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTag(db, js))
+
+	// js merges first, then lang fails because it has a child
+	_, err = into.Merge(db, []*Tag{js, parent})
+	assert.NotNil(t, err)
+
+	found, err := FindTagByName(db, "js")
+	assert.Nil(t, err)
+	assert.NotNil(t, found)
+	assert.Equal(t, js.ID, found.ID)
+	assert.Nil(t, js.LoadStars(db, ""))
+	assert.Equal(t, 1, len(js.Stars))
+	assert.Nil(t, into.LoadStars(db, ""))
+	assert.Equal(t, 0, len(into.Stars))
+}
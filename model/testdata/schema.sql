@@ -0,0 +1,16 @@
+-- The schema AutoMigrate created before limo had migrations, with some data to migrate
+CREATE TABLE IF NOT EXISTS "services" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"name" varchar(255),"last_success" datetime );
+CREATE INDEX idx_services_deleted_at ON "services"(deleted_at) ;
+CREATE TABLE IF NOT EXISTS "star_tags" ("star_id" integer,"tag_id" integer, PRIMARY KEY ("star_id","tag_id"));
+CREATE TABLE IF NOT EXISTS "stars" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"remote_id" varchar(255),"name" varchar(255),"full_name" varchar(255),"description" varchar(255),"homepage" varchar(255),"url" varchar(255),"language" varchar(255),"stargazers" integer,"starred_at" datetime,"service_id" integer );
+CREATE INDEX idx_stars_deleted_at ON "stars"(deleted_at) ;
+CREATE TABLE IF NOT EXISTS "tags" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"deleted_at" datetime,"name" varchar(255) );
+CREATE INDEX idx_tags_deleted_at ON "tags"(deleted_at) ;
+INSERT INTO "services" ("id","created_at","updated_at","name") VALUES (1,'2023-01-01 00:00:00','2023-01-01 00:00:00','github');
+INSERT INTO "stars" ("id","created_at","updated_at","remote_id","name","full_name","service_id") VALUES (1,'2023-01-01 00:00:00','2023-01-01 00:00:00','1','limo','hoop33/limo',1);
+INSERT INTO "tags" ("id","created_at","updated_at","name") VALUES (1,'2023-01-01 00:00:00','2023-01-01 00:00:00','cli');
+INSERT INTO "tags" ("id","created_at","updated_at","name") VALUES (2,'2023-01-01 00:00:00','2023-01-01 00:00:00','lang');
+INSERT INTO "tags" ("id","created_at","updated_at","name") VALUES (3,'2023-01-01 00:00:00','2023-01-01 00:00:00','lang/go');
+INSERT INTO "tags" ("id","created_at","updated_at","name") VALUES (4,'2023-01-01 00:00:00','2023-01-01 00:00:00','editors/vim');
+INSERT INTO "star_tags" ("star_id","tag_id") VALUES (1,1);
+INSERT INTO "star_tags" ("star_id","tag_id") VALUES (1,3);
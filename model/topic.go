@@ -0,0 +1,127 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TopicSource marks tags applied from a star's topics
+const TopicSource = "topic"
+
+// StarTag associates a star with a tag, recording where the tag came from --
+// tags you apply yourself have no source
+type StarTag struct {
+	StarID uint `gorm:"primary_key;auto_increment:false"`
+	TagID  uint `gorm:"primary_key;auto_increment:false"`
+	Source string
+}
+
+// TopicTagger decides which tags to apply from a star's topics
+type TopicTagger struct {
+	Allow  []string
+	Deny   []string
+	Rename map[string]string
+}
+
+// TagNames returns the tag names for the topics, dropping topics not allowed or denied
+func (tt *TopicTagger) TagNames(topics []string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, topic := range topics {
+		topic = strings.ToLower(strings.TrimSpace(topic))
+		if topic == "" || !tt.allows(topic) {
+			continue
+		}
+
+		name := topic
+		for from, to := range tt.Rename {
+			if strings.EqualFold(from, topic) {
+				name = to
+				break
+			}
+		}
+
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (tt *TopicTagger) allows(topic string) bool {
+	for _, denied := range tt.Deny {
+		if strings.EqualFold(denied, topic) {
+			return false
+		}
+	}
+	if len(tt.Allow) == 0 {
+		return true
+	}
+	for _, allowed := range tt.Allow {
+		if strings.EqualFold(allowed, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply tags a star from its topics, and removes the topic tags for topics it no longer has.
+// It returns the names of the tags added and removed
+func (tt *TopicTagger) Apply(db *gorm.DB, op *Operation, star *Star) ([]string, []string, error) {
+	if err := star.LoadTags(db); err != nil {
+		return nil, nil, err
+	}
+
+	wanted := make(map[string]bool)
+	var added []string
+	for _, name := range tt.TagNames(star.Topics) {
+		tag, _, err := op.FindOrCreateTagByName(db, name)
+		if err != nil {
+			return added, nil, err
+		}
+		wanted[strings.ToLower(tag.Name)] = true
+
+		// Leave tags you've applied yourself alone
+		if !star.HasTag(tag) {
+			if err := op.AddTagFromSource(db, star, tag, TopicSource); err != nil {
+				return added, nil, err
+			}
+			added = append(added, tag.Name)
+		}
+	}
+
+	var starTags []StarTag
+	if err := db.Where("star_id = ? AND source = ?", star.ID, TopicSource).Find(&starTags).Error; err != nil {
+		return added, nil, err
+	}
+
+	var removed []string
+	for _, starTag := range starTags {
+		tag, err := FindTagByID(db, starTag.TagID)
+		if err != nil {
+			return added, removed, err
+		}
+		if !wanted[strings.ToLower(tag.Name)] {
+			if err := op.RemoveTag(db, star, tag); err != nil {
+				return added, removed, err
+			}
+			removed = append(removed, tag.Name)
+		}
+	}
+	return added, removed, star.LoadTags(db)
+}
+
+// AddTagFromSource adds a tag to a star, recording where it came from
+func (star *Star) AddTagFromSource(db *gorm.DB, tag *Tag, source string) error {
+	if err := db.Create(&StarTag{
+		StarID: star.ID,
+		TagID:  tag.ID,
+		Source: source,
+	}).Error; err != nil {
+		return err
+	}
+	star.Tags = append(star.Tags, *tag)
+	return nil
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagNamesShouldNormalizeAndDeduplicate(t *testing.T) {
+	tt := &TopicTagger{
+		Rename: map[string]string{"golang": "go"},
+	}
+	assert.Equal(t, []string{"go", "cli"}, tt.TagNames([]string{"Golang", "go", "cli", " "}))
+}
+
+func TestTagNamesShouldApplyAllowAndDenyLists(t *testing.T) {
+	tt := &TopicTagger{
+		Allow: []string{"go", "cli", "hacktoberfest"},
+		Deny:  []string{"hacktoberfest"},
+	}
+	assert.Equal(t, []string{"go", "cli"}, tt.TagNames([]string{"go", "hacktoberfest", "cli", "awesome"}))
+}
+
+func TestApplyShouldTagFromTopicsAndKeepManualTags(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "1", Topics: Topics{"go", "cli"}}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	manual, _, err := FindOrCreateTagByName(db, "cli")
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTag(db, manual))
+
+	tt := &TopicTagger{}
+	added, removed, err := tt.Apply(db, nil, star)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"go"}, added)
+	assert.Equal(t, 0, len(removed))
+	assert.Equal(t, 2, len(star.Tags))
+
+	// Upstream drops both topics: only the topic tag goes
+	star.Topics = nil
+	added, removed, err = tt.Apply(db, nil, star)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(added))
+	assert.Equal(t, []string{"go"}, removed)
+	assert.Equal(t, 1, len(star.Tags))
+	assert.Equal(t, "cli", star.Tags[0].Name)
+}
+
+func TestApplyShouldBeIdempotent(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	star := &Star{RemoteID: "1", Topics: Topics{"go", "cli"}}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	tt := &TopicTagger{}
+	added, _, err := tt.Apply(db, nil, star)
+	assert.Nil(t, err)
+	sort.Strings(added)
+	assert.Equal(t, []string{"cli", "go"}, added)
+
+	added, removed, err := tt.Apply(db, nil, star)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(added))
+	assert.Equal(t, 0, len(removed))
+	assert.Equal(t, 2, len(star.Tags))
+}
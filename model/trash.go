@@ -0,0 +1,209 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// TagSnapshot saves what a tag had when it was deleted, so restoring the tag can put it back.
+// Each snapshot is either a star the tag was on, with the tag's source on that star, or one of its aliases
+type TagSnapshot struct {
+	ID     uint `gorm:"primary_key"`
+	TagID  uint `gorm:"index"`
+	StarID uint
+	Source string
+	Alias  string
+}
+
+// snapshot saves the tag's stars and aliases before deleting it
+func (tag *Tag) snapshot(db *gorm.DB) error {
+	if err := db.Where("tag_id = ?", tag.ID).Delete(&TagSnapshot{}).Error; err != nil {
+		return err
+	}
+
+	var starTags []StarTag
+	if err := db.Where("tag_id = ?", tag.ID).Find(&starTags).Error; err != nil {
+		return err
+	}
+	for _, starTag := range starTags {
+		if err := db.Create(&TagSnapshot{
+			TagID:  tag.ID,
+			StarID: starTag.StarID,
+			Source: starTag.Source,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	aliases, err := tag.FindAliases(db)
+	if err != nil {
+		return err
+	}
+	for _, alias := range aliases {
+		if err := db.Create(&TagSnapshot{
+			TagID: tag.ID,
+			Alias: alias.Name,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindTrashedStars finds the deleted stars, most recently deleted first
+func FindTrashedStars(db *gorm.DB) ([]Star, error) {
+	var stars []Star
+	db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc, full_name").Find(&stars)
+	return stars, db.Error
+}
+
+// FindTrashedTags finds the deleted tags, most recently deleted first
+func FindTrashedTags(db *gorm.DB) ([]Tag, error) {
+	var tags []Tag
+	db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc, name").Find(&tags)
+	return tags, db.Error
+}
+
+// FindTrashedStarByName finds the most recently deleted star with a full name
+func FindTrashedStarByName(db *gorm.DB, name string) (*Star, error) {
+	var star Star
+	if db.Unscoped().Where("deleted_at IS NOT NULL AND lower(full_name) = ?",
+		strings.ToLower(name)).Order("deleted_at desc").First(&star).RecordNotFound() {
+		return nil, fmt.Errorf("star '%s' not found in the trash", name)
+	}
+	return &star, db.Error
+}
+
+// FindTrashedTagByName finds the most recently deleted tag with a name
+func FindTrashedTagByName(db *gorm.DB, name string) (*Tag, error) {
+	var tag Tag
+	if db.Unscoped().Where("deleted_at IS NOT NULL AND lower(name) = ?",
+		strings.ToLower(CleanTagName(name))).Order("deleted_at desc").First(&tag).RecordNotFound() {
+		return nil, fmt.Errorf("tag '%s' not found in the trash", name)
+	}
+	return &tag, db.Error
+}
+
+// Restore takes a star out of the trash. It fails if the star has been starred again since
+func (star *Star) Restore(db *gorm.DB) error {
+	var count int
+	if err := db.Model(&Star{}).Where("remote_id = ? AND service_id = ?", star.RemoteID, star.ServiceID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("star '%s' has been starred again", stringOrEmpty(star.FullName))
+	}
+
+	if err := db.Unscoped().Model(star).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	star.DeletedAt = nil
+	return nil
+}
+
+// Restore takes a tag out of the trash, restoring any of its ancestors that are also in the trash,
+// and puts it back on the stars and aliases it had when it was deleted. It returns the IDs of the
+// stars it was put back on
+func (tag *Tag) Restore(db *gorm.DB) ([]uint, error) {
+	existing, err := FindTagByName(db, tag.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if !strings.EqualFold(existing.Name, tag.Name) {
+			return nil, fmt.Errorf("'%s' is now an alias for tag '%s'", tag.Name, existing.Name)
+		}
+		return nil, fmt.Errorf("tag '%s' already exists", existing.Name)
+	}
+
+	var starIDs []uint
+	if parentName := parentTagName(tag.Name); parentName != "" {
+		parent, err := FindTagByName(db, parentName)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			if parent, err = FindTrashedTagByName(db, parentName); err == nil {
+				starIDs, err = parent.Restore(db)
+			} else {
+				// The parent has been purged, so create it again
+				parent, _, err = FindOrCreateTagByName(db, parentName)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		tag.ParentID = &parent.ID
+	}
+
+	if err := db.Unscoped().Model(tag).UpdateColumns(map[string]interface{}{
+		"deleted_at": nil,
+		"parent_id":  tag.ParentID,
+	}).Error; err != nil {
+		return nil, err
+	}
+	tag.DeletedAt = nil
+
+	var snapshots []TagSnapshot
+	if err := db.Where("tag_id = ?", tag.ID).Order("id").Find(&snapshots).Error; err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Alias != "" {
+			// If something else has taken the alias's name since, leave it off
+			_ = tag.AddAlias(db, snapshot.Alias)
+			continue
+		}
+
+		star, err := FindStarByID(db, snapshot.StarID)
+		if err != nil {
+			// The star is in the trash or gone
+			continue
+		}
+		if err := star.AddTagFromSource(db, tag, snapshot.Source); err != nil {
+			return nil, err
+		}
+		starIDs = append(starIDs, star.ID)
+	}
+	return starIDs, db.Where("tag_id = ?", tag.ID).Delete(&TagSnapshot{}).Error
+}
+
+// PurgeTrash permanently deletes the stars and tags deleted before a time, and returns what it deleted
+func PurgeTrash(db *gorm.DB, before time.Time) ([]Star, []Tag, error) {
+	var stars []Star
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("full_name").Find(&stars).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, star := range stars {
+		for _, table := range []interface{}{&StarTag{}, &StarSnapshot{}, &TagSnapshot{}} {
+			if err := db.Where("star_id = ?", star.ID).Delete(table).Error; err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := db.Unscoped().Delete(&star).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var tags []Tag
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("name").Find(&tags).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, tag := range tags {
+		for _, table := range []interface{}{&StarTag{}, &TagAlias{}, &ListMapping{}, &TagSnapshot{}} {
+			if err := db.Unscoped().Where("tag_id = ?", tag.ID).Delete(table).Error; err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := db.Unscoped().Delete(&tag).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+	return stars, tags, nil
+}
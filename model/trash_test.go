@@ -0,0 +1,157 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreTagShouldRestoreItsStarsAndAliases(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	name := "a/b"
+	star := &Star{RemoteID: "ab", FullName: &name}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+
+	tag, _, err := FindOrCreateTagByName(db, "lang/go")
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTagFromSource(db, tag, TopicSource))
+	assert.Nil(t, tag.AddAlias(db, "golang"))
+
+	parent, err := FindTagByName(db, "lang")
+	assert.Nil(t, err)
+	assert.Nil(t, tag.Delete(db))
+	assert.Nil(t, parent.Delete(db))
+
+	tags, err := FindTrashedTags(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tags))
+
+	trashed, err := FindTrashedTagByName(db, "LANG/GO")
+	assert.Nil(t, err)
+	starIDs, err := trashed.Restore(db)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{star.ID}, starIDs)
+
+	restored, err := FindTagByName(db, "golang")
+	assert.Nil(t, err)
+	assert.Equal(t, "lang/go", restored.Name)
+
+	restoredParent, err := FindTagByName(db, "lang")
+	assert.Nil(t, err)
+	assert.Equal(t, restoredParent.ID, *restored.ParentID)
+
+	var starTag StarTag
+	assert.False(t, db.Where("star_id = ? AND tag_id = ?", star.ID, tag.ID).First(&starTag).RecordNotFound())
+	assert.Equal(t, TopicSource, starTag.Source)
+
+	tags, err = FindTrashedTags(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tags))
+}
+
+func TestRestoreTagShouldFailWhenNameIsTaken(t *testing.T) {
+	clearDB()
+
+	tag, _, err := FindOrCreateTagByName(db, "js")
+	assert.Nil(t, err)
+	assert.Nil(t, tag.Delete(db))
+
+	_, _, err = FindOrCreateTagByName(db, "js")
+	assert.Nil(t, err)
+
+	trashed, err := FindTrashedTagByName(db, "js")
+	assert.Nil(t, err)
+	_, err = trashed.Restore(db)
+	assert.NotNil(t, err)
+	assert.Equal(t, "tag 'js' already exists", err.Error())
+}
+
+func TestRestoreStarShouldUndeleteIt(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	name := "a/b"
+	star := &Star{RemoteID: "ab", FullName: &name}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+	assert.Nil(t, star.Delete(db))
+
+	stars, err := FindStars(db, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(stars))
+
+	trashed, err := FindTrashedStarByName(db, "A/B")
+	assert.Nil(t, err)
+	assert.Nil(t, trashed.Restore(db))
+
+	stars, err = FindStars(db, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stars))
+
+	_, err = FindTrashedStarByName(db, "a/b")
+	assert.NotNil(t, err)
+}
+
+func TestRestoreStarShouldFailWhenStarredAgain(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	name := "a/b"
+	star := &Star{RemoteID: "ab", FullName: &name}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+	assert.Nil(t, star.Delete(db))
+
+	_, err = CreateOrUpdateStar(db, &Star{RemoteID: "ab", FullName: &name}, service)
+	assert.Nil(t, err)
+
+	trashed, err := FindTrashedStarByName(db, "a/b")
+	assert.Nil(t, err)
+	assert.NotNil(t, trashed.Restore(db))
+}
+
+func TestPurgeTrashShouldDeleteOnlyOlderItems(t *testing.T) {
+	clearDB()
+
+	service, _, err := FindOrCreateServiceByName(db, "svc")
+	assert.Nil(t, err)
+
+	name := "a/b"
+	star := &Star{RemoteID: "ab", FullName: &name}
+	_, err = CreateOrUpdateStar(db, star, service)
+	assert.Nil(t, err)
+	tag, _, err := FindOrCreateTagByName(db, "old")
+	assert.Nil(t, err)
+	assert.Nil(t, star.AddTag(db, tag))
+
+	assert.Nil(t, star.Delete(db))
+	assert.Nil(t, tag.Delete(db))
+
+	stars, tags, err := PurgeTrash(db, time.Now().Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(stars))
+	assert.Equal(t, 0, len(tags))
+
+	stars, tags, err = PurgeTrash(db, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stars))
+	assert.Equal(t, 1, len(tags))
+
+	var count int
+	db.Unscoped().Model(&Star{}).Count(&count)
+	assert.Equal(t, 0, count)
+	db.Table("tag_snapshots").Count(&count)
+	assert.Equal(t, 0, count)
+	db.Table("star_tags").Count(&count)
+	assert.Equal(t, 0, count)
+}
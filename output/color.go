@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
@@ -113,6 +114,14 @@ func (c *Color) StarLine(star *model.Star) {
 	fmt.Println(buffer.String())
 }
 
+// TrendingLine displays a trending star in one line, marking it if you've starred it
+func (c *Color) TrendingLine(star *model.Star, starred bool) {
+	if starred {
+		fmt.Print(color.CyanString("(starred) "))
+	}
+	c.StarLine(star)
+}
+
 // Star displays a star
 func (c *Color) Star(star *model.Star) {
 	c.StarLine(star)
@@ -138,14 +147,53 @@ func (c *Color) Star(star *model.Star) {
 		color.Red(fmt.Sprintf("Home page: %s", *star.Homepage))
 	}
 
+	for _, line := range metadataLines(star) {
+		color.Yellow(line)
+	}
+
 	color.Green(fmt.Sprintf("Starred on %s", star.StarredAt.Format(time.UnixDate)))
+
+	if star.LocalPath != nil && *star.LocalPath != "" {
+		color.Cyan(fmt.Sprintf("Local path: %s", *star.LocalPath))
+	}
+
+	if star.Rating > 0 {
+		color.Yellow(fmt.Sprintf("Rating: %s%s", strings.Repeat("★", star.Rating), strings.Repeat("☆", model.MaxRating-star.Rating)))
+	}
+
+	if star.Note != nil && *star.Note != "" {
+		color.White(strings.TrimRight(*star.Note, "\n"))
+	}
+}
+
+// Stats displays statistics
+func (c *Color) Stats(stats *model.Stats) {
+	color.Blue(fmt.Sprintf("Stars: %d", stats.Total))
+	color.Yellow(fmt.Sprintf("Untagged: %d (%.1f%%)", stats.Untagged, stats.UntaggedPercent))
+	color.Yellow(fmt.Sprintf("Median stargazers: %g", stats.MedianStargazers))
+
+	for _, section := range []struct {
+		title  string
+		counts []model.Count
+	}{
+		{"Services", stats.Services},
+		{"Languages", stats.Languages},
+		{"Tags", stats.Tags},
+		{"Starred by year", stats.Years},
+		{"Starred by month", lastCounts(stats.Months, recentMonths)},
+	} {
+		color.Blue(fmt.Sprintf("\n%s", section.title))
+		for _, line := range formatCounts(section.counts) {
+			color.Green(line)
+		}
+	}
 }
 
 // Tag displays a tag
 func (c *Color) Tag(tag *model.Tag) {
 	var buffer bytes.Buffer
 
-	_, err := buffer.WriteString(color.BlueString(tag.Name))
+	_, err := buffer.WriteString(color.BlueString(tagLabel(tag)))
 	if err != nil {
 		c.Error(err.Error())
 	}
@@ -158,6 +206,28 @@ func (c *Color) Tag(tag *model.Tag) {
 	fmt.Println(buffer.String())
 }
 
+// Trend displays how a star's stargazers changed, with a sparkline of its history
+func (c *Color) Trend(trend *model.Trend) {
+	change := color.GreenString
+	if trend.Change < 0 {
+		change = color.RedString
+	}
+
+	fmt.Printf("%s %s %s %s\n",
+		color.BlueString(*trend.Star.FullName),
+		color.YellowString(fmt.Sprintf("★ :%d", trend.Star.Stargazers)),
+		change(fmt.Sprintf("%+d (%+.1f%%)", trend.Change, trend.Percent)),
+		color.CyanString(sparkline(trend.History)))
+}
+
+// Snapshot displays a star's counts on a day
+func (c *Color) Snapshot(snapshot *model.StarSnapshot) {
+	fmt.Printf("%s %s %s\n",
+		color.GreenString(snapshot.Date.Format("2006-01-02")),
+		color.YellowString(fmt.Sprintf("★ :%d", snapshot.Stargazers)),
+		fmt.Sprintf("Forks: %d; Open issues: %d", snapshot.Forks, snapshot.OpenIssues))
+}
+
 // Tick displays evidence that the program is working
 func (c *Color) Tick() {
 	if spin == nil {
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hoop33/limo/config"
+	"github.com/hoop33/limo/model"
+)
+
+// JSON is a JSON output, one value per line, for use in scripts
+type JSON struct {
+}
+
+// Configure no-ops
+func (j *JSON) Configure(oc *config.OutputConfig) {
+}
+
+// Inline displays text as a JSON string
+func (j *JSON) Inline(s string) {
+	j.write(s)
+}
+
+// Info displays information as a JSON string
+func (j *JSON) Info(s string) {
+	j.write(s)
+}
+
+// Error displays an error
+func (j *JSON) Error(s string) {
+	data, err := json.Marshal(map[string]string{
+		"error": s,
+	})
+	if err != nil {
+		data = []byte(s)
+	}
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// Fatal displays an error and ends the program
+func (j *JSON) Fatal(s string) {
+	j.Error(s)
+	os.Exit(1)
+}
+
+// Event displays an event
+func (j *JSON) Event(event *model.Event) {
+	j.write(event)
+}
+
+// StarLine displays a star
+func (j *JSON) StarLine(star *model.Star) {
+	j.write(star)
+}
+
+// TrendingLine displays a trending star and whether you've starred it
+func (j *JSON) TrendingLine(star *model.Star, starred bool) {
+	j.write(struct {
+		*model.Star
+		Starred bool
+	}{star, starred})
+}
+
+// Star displays a star
+func (j *JSON) Star(star *model.Star) {
+	j.write(star)
+}
+
+// Stats displays statistics
+func (j *JSON) Stats(stats *model.Stats) {
+	j.write(stats)
+}
+
+// Tag displays a tag
+func (j *JSON) Tag(tag *model.Tag) {
+	j.write(tag)
+}
+
+// Trend displays how a star's stargazers changed
+func (j *JSON) Trend(trend *model.Trend) {
+	j.write(trend)
+}
+
+// Snapshot displays a star's counts on a day
+func (j *JSON) Snapshot(snapshot *model.StarSnapshot) {
+	j.write(snapshot)
+}
+
+// Tick no-ops, so it doesn't interfere with the JSON
+func (j *JSON) Tick() {
+}
+
+func (j *JSON) write(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		j.Error(err.Error())
+		return
+	}
+	fmt.Println(string(data))
+}
+
+func init() {
+	registerOutput(&JSON{})
+}
@@ -0,0 +1,30 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hoop33/limo/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var jsonOutput JSON
+
+func TestJSONDoesRegisterItself(t *testing.T) {
+	assert.Equal(t, "*output.JSON", reflect.TypeOf(ForName("json")).String())
+}
+
+func ExampleJSON_Info() {
+	jsonOutput.Info("This is info")
+	// Output: "This is info"
+}
+
+func ExampleJSON_Stats() {
+	jsonOutput.Stats(&model.Stats{
+		Total: 1,
+		Languages: []model.Count{
+			{Name: "Go", Count: 1, Percent: 100},
+		},
+	})
+	// Output: {"Total":1,"Untagged":0,"UntaggedPercent":0,"MedianStargazers":0,"Services":null,"Languages":[{"Name":"Go","Count":1,"Percent":100}],"Tags":null,"Years":null,"Months":null}
+}
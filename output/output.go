@@ -1,8 +1,10 @@
 package output
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/hoop33/limo/config"
 	"github.com/hoop33/limo/model"
@@ -18,8 +20,12 @@ type Output interface {
 	Fatal(string)
 	StarLine(*model.Star)
 	Star(*model.Star)
+	TrendingLine(*model.Star, bool)
+	Stats(*model.Stats)
 	Tag(*model.Tag)
 	Tick()
+	Trend(*model.Trend)
+	Snapshot(*model.StarSnapshot)
 }
 
 var outputs = make(map[string]Output)
@@ -37,3 +43,106 @@ func ForName(name string) Output {
 	// We always want an output, so default to text
 	return outputs["text"]
 }
+
+const barWidth = 40
+const recentMonths = 12
+
+func formatCounts(counts []model.Count) []string {
+	width, max := 0, 0
+	for _, count := range counts {
+		if len(count.Name) > width {
+			width = len(count.Name)
+		}
+		if count.Count > max {
+			max = count.Count
+		}
+	}
+
+	lines := make([]string, 0, len(counts))
+	for _, count := range counts {
+		lines = append(lines, fmt.Sprintf("  %-*s %6d %5.1f%% %s", width, count.Name, count.Count, count.Percent, bar(count.Count, max)))
+	}
+	return lines
+}
+
+func bar(count, max int) string {
+	if max == 0 {
+		return ""
+	}
+	return strings.Repeat("#", count*barWidth/max)
+}
+
+func lastCounts(counts []model.Count, n int) []model.Count {
+	if len(counts) <= n {
+		return counts
+	}
+	return counts[len(counts)-n:]
+}
+
+// sparkTicks are the bars of a sparkline, lowest first
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a line of bars scaled from the lowest value to the highest
+func sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	low, high := values[0], values[0]
+	for _, value := range values {
+		if value < low {
+			low = value
+		}
+		if value > high {
+			high = value
+		}
+	}
+
+	line := make([]rune, len(values))
+	for i, value := range values {
+		tick := 0
+		if high > low {
+			tick = (value - low) * (len(sparkTicks) - 1) / (high - low)
+		}
+		line[i] = sparkTicks[tick]
+	}
+	return string(line)
+}
+
+// tagLabel returns how a tag appears in a tag tree: indented by its depth, showing only its leaf
+func tagLabel(tag *model.Tag) string {
+	if tag.Depth == 0 {
+		return tag.Name
+	}
+	return strings.Repeat("  ", tag.Depth) + tag.Leaf()
+}
+
+// metadataLines describes the repository behind a star, skipping what the service didn't provide
+func metadataLines(star *model.Star) []string {
+	var lines []string
+	if len(star.Topics) > 0 {
+		lines = append(lines, fmt.Sprintf("Topics: %s", strings.Join(star.Topics, ", ")))
+	}
+	if star.License != nil && *star.License != "" {
+		lines = append(lines, fmt.Sprintf("License: %s", *star.License))
+	}
+	if star.Forks > 0 || star.OpenIssues > 0 {
+		lines = append(lines, fmt.Sprintf("Forks: %d; Open issues: %d", star.Forks, star.OpenIssues))
+	}
+	if star.DefaultBranch != nil && *star.DefaultBranch != "" {
+		lines = append(lines, fmt.Sprintf("Default branch: %s", *star.DefaultBranch))
+	}
+	if star.Fork {
+		lines = append(lines, "Fork")
+	}
+	if star.Archived {
+		lines = append(lines, "Archived")
+	}
+	if star.RemoteCreatedAt != nil {
+		lines = append(lines, fmt.Sprintf("Created on %s", star.RemoteCreatedAt.Format(time.UnixDate)))
+	}
+	if star.PushedAt != nil {
+		lines = append(lines, fmt.Sprintf("Last pushed on %s", star.PushedAt.Format(time.UnixDate)))
+	}
+	return lines
+}
@@ -10,3 +10,10 @@ import (
 func TestForNameReturnsTextWhenNameNotFound(t *testing.T) {
 	assert.Equal(t, "*output.Text", reflect.TypeOf(ForName("foo")).String())
 }
+
+func TestSparklineScalesFromLowestToHighest(t *testing.T) {
+	assert.Equal(t, "", sparkline(nil))
+	assert.Equal(t, "▁▁▁", sparkline([]int{5, 5, 5}))
+	assert.Equal(t, "▁▄█", sparkline([]int{100, 150, 200}))
+	assert.Equal(t, "█▁▂", sparkline([]int{400, 100, 150}))
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -75,6 +76,14 @@ func (t *Text) StarLine(star *model.Star) {
 	fmt.Println(buffer.String())
 }
 
+// TrendingLine displays a trending star in one line, marking it if you've starred it
+func (t *Text) TrendingLine(star *model.Star, starred bool) {
+	if starred {
+		fmt.Print("(starred) ")
+	}
+	t.StarLine(star)
+}
+
 // Star displays a star
 func (t *Text) Star(star *model.Star) {
 	t.StarLine(star)
@@ -100,12 +109,62 @@ func (t *Text) Star(star *model.Star) {
 		fmt.Printf("Home page: %s\n", *star.Homepage)
 	}
 
+	for _, line := range metadataLines(star) {
+		fmt.Println(line)
+	}
+
 	fmt.Printf("Starred on %s\n", star.StarredAt.Format(time.UnixDate))
+
+	if star.LocalPath != nil && *star.LocalPath != "" {
+		fmt.Printf("Local path: %s\n", *star.LocalPath)
+	}
+
+	if star.Rating > 0 {
+		fmt.Printf("Rating: %d/%d\n", star.Rating, model.MaxRating)
+	}
+
+	if star.Note != nil && *star.Note != "" {
+		fmt.Printf("Note:\n%s\n", strings.TrimRight(*star.Note, "\n"))
+	}
+}
+
+// Stats displays statistics
+func (t *Text) Stats(stats *model.Stats) {
+	fmt.Printf("Stars: %d\n", stats.Total)
+	fmt.Printf("Untagged: %d (%.1f%%)\n", stats.Untagged, stats.UntaggedPercent)
+	fmt.Printf("Median stargazers: %g\n", stats.MedianStargazers)
+
+	for _, section := range []struct {
+		title  string
+		counts []model.Count
+	}{
+		{"Services", stats.Services},
+		{"Languages", stats.Languages},
+		{"Tags", stats.Tags},
+		{"Starred by year", stats.Years},
+		{"Starred by month", lastCounts(stats.Months, recentMonths)},
+	} {
+		fmt.Printf("\n%s\n", section.title)
+		for _, line := range formatCounts(section.counts) {
+			fmt.Println(line)
+		}
+	}
 }
 
 // Tag displays a tag
 func (t *Text) Tag(tag *model.Tag) {
-	fmt.Printf("%s *:%d\n", tag.Name, tag.StarCount)
+	fmt.Printf("%s *:%d\n", tagLabel(tag), tag.StarCount)
+}
+
+// Trend displays how a star's stargazers changed
+func (t *Text) Trend(trend *model.Trend) {
+	fmt.Printf("%s *:%d %+d (%+.1f%%)\n", *trend.Star.FullName, trend.Star.Stargazers, trend.Change, trend.Percent)
+}
+
+// Snapshot displays a star's counts on a day
+func (t *Text) Snapshot(snapshot *model.StarSnapshot) {
+	fmt.Printf("%s *:%d Forks: %d; Open issues: %d\n", snapshot.Date.Format("2006-01-02"),
+		snapshot.Stargazers, snapshot.Forks, snapshot.OpenIssues)
 }
 
 // Tick displays evidence that the program is working
@@ -42,6 +42,19 @@ func ExampleText_StarLine() {
 	// Output: hoop33/limo *:1000000 Go
 }
 
+func ExampleText_TrendingLine() {
+	fullName := "hoop33/limo"
+	star := &model.Star{
+		FullName:   &fullName,
+		Stargazers: 1000,
+	}
+	text.TrendingLine(star, true)
+	text.TrendingLine(star, false)
+	// Output:
+	// (starred) hoop33/limo *:1000
+	// hoop33/limo *:1000
+}
+
 func ExampleText_Star() {
 	fullName := "hoop33/limo"
 	language := "Go"
@@ -73,3 +86,113 @@ func ExampleText_Star() {
 	// Home page: https://github.com/hoop33/limo
 	// Starred on Tue Jun 21 14:56:05 UTC 2016
 }
+
+func ExampleText_Star_metadata() {
+	fullName := "hoop33/limo"
+	license := "MIT"
+	pushedAt := time.Date(2018, time.May, 1, 12, 0, 0, 0, time.UTC)
+	star := &model.Star{
+		FullName:   &fullName,
+		Stargazers: 1000000,
+		StarredAt:  time.Date(2016, time.June, 21, 14, 56, 5, 0, time.UTC),
+		Topics:     model.Topics{"cli", "github"},
+		License:    &license,
+		Forks:      42,
+		Archived:   true,
+		PushedAt:   &pushedAt,
+	}
+	text.Star(star)
+	// Output:
+	// hoop33/limo *:1000000
+	// Topics: cli, github
+	// License: MIT
+	// Forks: 42; Open issues: 0
+	// Archived
+	// Last pushed on Tue May  1 12:00:00 UTC 2018
+	// Starred on Tue Jun 21 14:56:05 UTC 2016
+}
+
+func ExampleText_Tag() {
+	for _, tag := range []model.Tag{
+		{Name: "lang", StarCount: 3},
+		{Name: "lang/go", StarCount: 2, Depth: 1},
+		{Name: "lang/go/web", StarCount: 1, Depth: 2},
+	} {
+		text.Tag(&tag)
+	}
+	// Output:
+	// lang *:3
+	//   go *:2
+	//     web *:1
+}
+
+func ExampleText_Star_note() {
+	fullName := "hoop33/limo"
+	note := "Use this to *find* things.\n\n- fast\n"
+	star := &model.Star{
+		FullName:   &fullName,
+		Stargazers: 1000000,
+		StarredAt:  time.Date(2016, time.June, 21, 14, 56, 5, 0, time.UTC),
+		Rating:     4,
+		Note:       &note,
+	}
+	text.Star(star)
+	// Output:
+	// hoop33/limo *:1000000
+	// Starred on Tue Jun 21 14:56:05 UTC 2016
+	// Rating: 4/5
+	// Note:
+	// Use this to *find* things.
+	//
+	// - fast
+}
+
+func ExampleText_Trend() {
+	fullName := "hoop33/limo"
+	text.Trend(&model.Trend{
+		Star:    model.Star{FullName: &fullName, Stargazers: 400},
+		Change:  300,
+		Percent: 300,
+		History: []int{100, 200, 400},
+	})
+	// Output: hoop33/limo *:400 +300 (+300.0%)
+}
+
+func ExampleText_Snapshot() {
+	text.Snapshot(&model.StarSnapshot{
+		Date:       time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC),
+		Stargazers: 400,
+		Forks:      12,
+		OpenIssues: 3,
+	})
+	// Output: 2018-05-01 *:400 Forks: 12; Open issues: 3
+}
+
+func ExampleText_Stats() {
+	text.Stats(&model.Stats{
+		Total:            4,
+		Untagged:         1,
+		UntaggedPercent:  25,
+		MedianStargazers: 12.5,
+		Languages: []model.Count{
+			{Name: "Go", Count: 3, Percent: 75},
+			{Name: "Rust", Count: 1, Percent: 25},
+		},
+	})
+	// Output:
+	// Stars: 4
+	// Untagged: 1 (25.0%)
+	// Median stargazers: 12.5
+	//
+	// Services
+	//
+	// Languages
+	//   Go        3  75.0% ########################################
+	//   Rust      1  25.0% #############
+	//
+	// Tags
+	//
+	// Starred by year
+	//
+	// Starred by month
+}
@@ -0,0 +1,566 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blevesearch/bleve"
+	"github.com/hoop33/limo/model"
+	"github.com/jinzhu/gorm"
+)
+
+const maxHits = 100
+
+// Updater updates the stars from the named service, returning the numbers created, updated, and in error
+type Updater func(ctx context.Context, serviceName string) (int, int, int, error)
+
+// Server serves your stars and tags over a REST API
+type Server struct {
+	db      *gorm.DB
+	index   bleve.Index
+	updater Updater
+	addr    string
+	mutex   sync.Mutex
+}
+
+// Hit is a search result
+type Hit struct {
+	Score float64
+	Star  *model.Star
+}
+
+// UpdateResult contains the totals from an update
+type UpdateResult struct {
+	Created int
+	Updated int
+	Errors  int
+}
+
+type tagRequest struct {
+	Name string
+}
+
+type bulkTagRequest struct {
+	Stars  []uint
+	Add    []string
+	Remove []string
+}
+
+type updateRequest struct {
+	Service string
+}
+
+// errNotFound is returned when a route or record doesn't exist
+var errNotFound = errors.New("not found")
+
+// New creates a server listening on addr -- updater may be nil, which disables updating
+func New(db *gorm.DB, index bleve.Index, updater Updater, addr string) *Server {
+	return &Server{
+		db:      db,
+		index:   index,
+		updater: updater,
+		addr:    addr,
+	}
+}
+
+// ServeHTTP routes API requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if status, err := s.checkRequest(r); err != nil {
+		writeJSON(w, status, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// SQLite allows one writer, so handle one request at a time
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api"), "/"), "/")
+
+	// Record the changes a request makes, so they show in history and can be undone
+	op := model.NewOperation(fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+
+	var v interface{}
+	var err error
+	status := http.StatusOK
+
+	switch {
+	case route(r, parts, http.MethodGet, "stars"):
+		v, err = s.listStars(r)
+	case route(r, parts, http.MethodGet, "stars", "*"):
+		v, err = s.getStar(parts[1])
+	case route(r, parts, http.MethodPost, "stars", "*", "tags"):
+		v, err = s.tagStar(r, op, parts[1])
+	case route(r, parts, http.MethodDelete, "stars", "*", "tags", "*"):
+		v, err = s.untagStar(op, parts[1], parts[3])
+	case route(r, parts, http.MethodGet, "tags"):
+		v, err = model.FindTagsWithStarCount(s.db)
+	case route(r, parts, http.MethodPost, "tags"):
+		v, err = s.createTag(r, op)
+		status = http.StatusCreated
+	case route(r, parts, http.MethodGet, "tags", "*"):
+		v, err = s.getTag(parts[1])
+	case route(r, parts, http.MethodPut, "tags", "*"):
+		v, err = s.renameTag(r, op, parts[1])
+	case route(r, parts, http.MethodDelete, "tags", "*"):
+		err = s.deleteTag(op, parts[1])
+		status = http.StatusNoContent
+	case route(r, parts, http.MethodPost, "bulk", "tags"):
+		v, err = s.bulkTag(r, op)
+	case route(r, parts, http.MethodGet, "languages"):
+		v, err = model.FindLanguages(s.db)
+	case route(r, parts, http.MethodGet, "services"):
+		v, err = model.FindServices(s.db)
+	case route(r, parts, http.MethodGet, "search"):
+		v, err = s.search(r)
+	case route(r, parts, http.MethodPost, "update"):
+		v, err = s.update(r)
+	default:
+		err = errNotFound
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, v)
+}
+
+// route returns whether the request matches the method and path, where "*" matches any path element
+func route(r *http.Request, parts []string, method string, pattern ...string) bool {
+	if r.Method != method || len(parts) != len(pattern) {
+		return false
+	}
+	for i, part := range pattern {
+		if part != "*" && part != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) listStars(r *http.Request) ([]model.Star, error) {
+	query := r.URL.Query()
+	match := query.Get("match")
+
+	var stars []model.Star
+	var err error
+	if untagged, _ := strconv.ParseBool(query.Get("untagged")); untagged {
+		stars, err = model.FindUntaggedStars(s.db, match)
+	} else {
+		stars, err = model.FindStarsByLanguageAndTag(s.db, match, query.Get("language"), query.Get("tag"), false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if serviceName := query.Get("service"); serviceName != "" {
+		stars, err = s.filterByService(stars, serviceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range stars {
+		if err := stars[i].LoadTags(s.db); err != nil {
+			return nil, err
+		}
+	}
+	return stars, nil
+}
+
+func (s *Server) filterByService(stars []model.Star, serviceName string) ([]model.Star, error) {
+	services, err := model.FindServices(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]model.Star, 0, len(stars))
+	for _, service := range services {
+		if strings.EqualFold(service.Name, serviceName) {
+			for _, star := range stars {
+				if star.ServiceID == service.ID {
+					filtered = append(filtered, star)
+				}
+			}
+		}
+	}
+	return filtered, nil
+}
+
+func (s *Server) getStar(id string) (*model.Star, error) {
+	ID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	star, err := model.FindStarByID(s.db, ID)
+	if err != nil {
+		return nil, errNotFound
+	}
+	return star, star.LoadTags(s.db)
+}
+
+func (s *Server) tagStar(r *http.Request, op *model.Operation, id string) (*model.Star, error) {
+	star, err := s.getStar(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var req tagRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	tag, _, err := op.FindOrCreateTagByName(s.db, req.Name)
+	if err != nil {
+		return nil, clientError(err)
+	}
+
+	if !star.HasTag(tag) {
+		if err := op.AddTag(s.db, star, tag); err != nil {
+			return nil, err
+		}
+	}
+	return star, s.reindex(*star)
+}
+
+func (s *Server) untagStar(op *model.Operation, id string, tagID string) (*model.Star, error) {
+	star, err := s.getStar(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.getTag(tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if star.HasTag(tag) {
+		if err := op.RemoveTag(s.db, star, tag); err != nil {
+			return nil, err
+		}
+	}
+	return star, s.reindex(*star)
+}
+
+func (s *Server) bulkTag(r *http.Request, op *model.Operation) ([]model.Star, error) {
+	var req bulkTagRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	var add, remove []*model.Tag
+	for _, name := range req.Add {
+		tag, _, err := op.FindOrCreateTagByName(s.db, name)
+		if err != nil {
+			return nil, clientError(err)
+		}
+		add = append(add, tag)
+	}
+	for _, name := range req.Remove {
+		tag, err := model.FindTagByName(s.db, name)
+		if err != nil {
+			return nil, err
+		}
+		if tag != nil {
+			remove = append(remove, tag)
+		}
+	}
+
+	stars := make([]model.Star, 0, len(req.Stars))
+	for _, ID := range req.Stars {
+		star, err := model.FindStarByID(s.db, ID)
+		if err != nil {
+			return nil, badRequest(err.Error())
+		}
+		if err := star.LoadTags(s.db); err != nil {
+			return nil, err
+		}
+		for _, tag := range add {
+			if !star.HasTag(tag) {
+				if err := op.AddTag(s.db, star, tag); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for _, tag := range remove {
+			if star.HasTag(tag) {
+				if err := op.RemoveTag(s.db, star, tag); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if err := star.Index(s.index, s.db); err != nil {
+			return nil, err
+		}
+		stars = append(stars, *star)
+	}
+	return stars, nil
+}
+
+func (s *Server) createTag(r *http.Request, op *model.Operation) (*model.Tag, error) {
+	var req tagRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	existing, err := model.FindTagByName(s.db, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, badRequest(fmt.Sprintf("tag '%s' already exists", existing.Name))
+	}
+
+	tag, _, err := op.FindOrCreateTagByName(s.db, req.Name)
+	return tag, clientError(err)
+}
+
+func (s *Server) getTag(id string) (*model.Tag, error) {
+	ID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := model.FindTagByID(s.db, ID)
+	if err != nil {
+		return nil, errNotFound
+	}
+	return tag, tag.LoadStars(s.db, "")
+}
+
+func (s *Server) renameTag(r *http.Request, op *model.Operation, id string) (*model.Tag, error) {
+	tag, err := s.getTag(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var req tagRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+
+	if err := op.RenameTag(s.db, tag, req.Name); err != nil {
+		return nil, clientError(err)
+	}
+	return tag, s.reindex(tag.Stars...)
+}
+
+func (s *Server) deleteTag(op *model.Operation, id string) error {
+	tag, err := s.getTag(id)
+	if err != nil {
+		return err
+	}
+
+	if err := op.DeleteTag(s.db, tag); err != nil {
+		return clientError(err)
+	}
+	return s.reindex(tag.Stars...)
+}
+
+func (s *Server) search(r *http.Request) ([]Hit, error) {
+	query := r.URL.Query()
+	q := query.Get("q")
+	if q == "" {
+		return nil, badRequest("you must specify a search string with [q]")
+	}
+
+	// Search-as-you-type treats the last word as a prefix
+	var searchQuery bleve.Query = bleve.NewMatchQuery(q)
+	if prefix, _ := strconv.ParseBool(query.Get("prefix")); prefix {
+		searchQuery = model.NewIncrementalQuery(q)
+	}
+
+	request := bleve.NewSearchRequestOptions(searchQuery, maxHits, 0, false)
+	results, err := s.index.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, 0, len(results.Hits))
+	for _, result := range results.Hits {
+		ID, err := strconv.Atoi(result.ID)
+		if err != nil {
+			return nil, err
+		}
+		star, err := model.FindStarByID(s.db, uint(ID))
+		if err != nil {
+			// The index can refer to stars that have since been deleted
+			continue
+		}
+		if err := star.LoadTags(s.db); err != nil {
+			return nil, err
+		}
+		hits = append(hits, Hit{
+			Score: result.Score,
+			Star:  star,
+		})
+	}
+	return hits, nil
+}
+
+func (s *Server) update(r *http.Request) (*UpdateResult, error) {
+	if s.updater == nil {
+		return nil, badRequest("updating is not enabled")
+	}
+
+	var req updateRequest
+	if r.ContentLength > 0 {
+		if err := decode(r, &req); err != nil {
+			return nil, err
+		}
+	}
+
+	created, updated, errs, err := s.updater(r.Context(), req.Service)
+	if err != nil {
+		return nil, err
+	}
+	return &UpdateResult{
+		Created: created,
+		Updated: updated,
+		Errors:  errs,
+	}, nil
+}
+
+// reindex updates the search index for stars whose tags have changed
+func (s *Server) reindex(stars ...model.Star) error {
+	for i := range stars {
+		if err := stars[i].Index(s.index, s.db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// badRequest is an error caused by the client
+type badRequest string
+
+func (e badRequest) Error() string {
+	return string(e)
+}
+
+// clientError turns a change the model can't make as asked into a bad request
+func clientError(err error) error {
+	if invalid, ok := err.(model.InvalidError); ok {
+		return badRequest(invalid.Error())
+	}
+	return err
+}
+
+// checkRequest keeps other web sites from using the API through your browser. Requests must be
+// addressed to the server, which stops DNS rebinding, and changes must come from the server's own
+// pages as JSON, which a cross-site form or simple request can't send
+func (s *Server) checkRequest(r *http.Request) (int, error) {
+	if !s.allowedHost(r.Host) {
+		return http.StatusForbidden, fmt.Errorf("host '%s' not allowed", r.Host)
+	}
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return http.StatusOK, nil
+	}
+
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || !s.allowedHost(u.Host) {
+			return http.StatusForbidden, fmt.Errorf("origin '%s' not allowed", origin)
+		}
+	}
+
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			return http.StatusUnsupportedMediaType, errors.New("the Content-Type must be application/json")
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// allowedHost returns whether a request's host names the address the server listens on. A server
+// on a loopback address answers to any loopback name, and one on every address answers to any name
+func (s *Server) allowedHost(host string) bool {
+	addrHost, addrPort, err := net.SplitHostPort(s.addr)
+	if err != nil {
+		return strings.EqualFold(host, s.addr)
+	}
+
+	requestHost, requestPort, err := net.SplitHostPort(host)
+	if err != nil {
+		requestHost, requestPort = strings.Trim(host, "[]"), "80"
+	}
+	if requestPort != addrPort {
+		return false
+	}
+
+	switch {
+	case addrHost == "" || isUnspecified(addrHost):
+		return true
+	case isLoopback(addrHost):
+		return isLoopback(requestHost)
+	default:
+		return strings.EqualFold(requestHost, addrHost)
+	}
+}
+
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func isUnspecified(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsUnspecified()
+}
+
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest(fmt.Sprintf("invalid request body: %s", err.Error()))
+	}
+	if req, ok := v.(*tagRequest); ok && strings.TrimSpace(req.Name) == "" {
+		return badRequest("you must specify a name")
+	}
+	return nil
+}
+
+func parseID(id string) (uint, error) {
+	ID, err := strconv.ParseUint(id, 10, 0)
+	if err != nil {
+		return 0, badRequest(fmt.Sprintf("invalid ID '%s'", id))
+	}
+	return uint(ID), nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if err == errNotFound {
+		status = http.StatusNotFound
+	} else if _, ok := err.(badRequest); ok {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, map[string]string{
+		"error": err.Error(),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintln(w, err.Error())
+	}
+}
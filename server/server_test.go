@@ -0,0 +1,409 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blevesearch/bleve"
+	"github.com/hoop33/limo/model"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+// testAddr is the address the test servers pretend to listen on
+const testAddr = "127.0.0.1:8080"
+
+var db *gorm.DB
+var index bleve.Index
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "limo-server")
+	if err != nil {
+		panic(err)
+	}
+
+	db, err = model.InitDB(filepath.Join(dir, "limo.db"), false)
+	if err != nil {
+		panic(err)
+	}
+	index, err = model.InitIndex(filepath.Join(dir, "limo.idx"))
+	if err != nil {
+		panic(err)
+	}
+
+	run := m.Run()
+	index.Close()
+	db.Close()
+	os.RemoveAll(dir)
+	os.Exit(run)
+}
+
+func setUp(t *testing.T) *Server {
+	for _, table := range []string{
+		"services",
+		"stars",
+		"tags",
+		"star_tags",
+		"operations",
+		"operation_steps",
+	} {
+		db.Exec(fmt.Sprintf("delete from %s", table))
+	}
+
+	service, _, err := model.FindOrCreateServiceByName(db, "github")
+	assert.Nil(t, err)
+
+	for i, name := range []string{"hoop33/limo", "spf13/cobra", "jaxbot/github-issues.vim"} {
+		fullName := name
+		language := "Go"
+		if strings.HasSuffix(name, ".vim") {
+			language = "VimL"
+		}
+		star := &model.Star{
+			RemoteID: fmt.Sprintf("%d", i),
+			FullName: &fullName,
+			Language: &language,
+		}
+		_, err = model.CreateOrUpdateStar(db, star, service)
+		assert.Nil(t, err)
+		assert.Nil(t, star.Index(index, db))
+	}
+
+	return New(db, index, nil, testAddr)
+}
+
+// newRequest creates a request addressed to the test server, sending any body as JSON
+func newRequest(method, path, body string) *http.Request {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Host = testAddr
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return r
+}
+
+func do(t *testing.T, s *Server, method, path, body string, v interface{}) int {
+	r := newRequest(method, path, body)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if v != nil {
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), v), w.Body.String())
+	}
+	return w.Code
+}
+
+func starID(t *testing.T, fullName string) uint {
+	stars, err := model.FuzzyFindStarsByName(db, fullName)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stars))
+	return stars[0].ID
+}
+
+func TestServerShouldListStars(t *testing.T) {
+	s := setUp(t)
+
+	var stars []model.Star
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/stars", "", &stars))
+	assert.Equal(t, 3, len(stars))
+}
+
+func TestServerShouldFilterStarsByLanguageAndMatch(t *testing.T) {
+	s := setUp(t)
+
+	var stars []model.Star
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/stars?language=Go", "", &stars))
+	assert.Equal(t, 2, len(stars))
+
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/stars?language=Go&match=cob", "", &stars))
+	assert.Equal(t, 1, len(stars))
+	assert.Equal(t, "spf13/cobra", *stars[0].FullName)
+}
+
+func TestServerShouldTagAndFilterStars(t *testing.T) {
+	s := setUp(t)
+	path := fmt.Sprintf("/api/stars/%d/tags", starID(t, "hoop33/limo"))
+
+	var star model.Star
+	assert.Equal(t, http.StatusOK, do(t, s, "POST", path, `{"name":"cli"}`, &star))
+	assert.Equal(t, 1, len(star.Tags))
+	assert.Equal(t, "cli", star.Tags[0].Name)
+
+	var stars []model.Star
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/stars?tag=cli", "", &stars))
+	assert.Equal(t, 1, len(stars))
+	assert.Equal(t, "hoop33/limo", *stars[0].FullName)
+
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/stars?untagged=true", "", &stars))
+	assert.Equal(t, 2, len(stars))
+}
+
+func TestServerShouldUntagStar(t *testing.T) {
+	s := setUp(t)
+	path := fmt.Sprintf("/api/stars/%d/tags", starID(t, "hoop33/limo"))
+
+	var star model.Star
+	assert.Equal(t, http.StatusOK, do(t, s, "POST", path, `{"name":"cli"}`, &star))
+	assert.Equal(t, http.StatusOK, do(t, s, "DELETE", fmt.Sprintf("%s/%d", path, star.Tags[0].ID), "", &star))
+	assert.Equal(t, 0, len(star.Tags))
+}
+
+func TestServerShouldRecordChangesSoTheyCanBeUndone(t *testing.T) {
+	s := setUp(t)
+	path := fmt.Sprintf("/api/stars/%d/tags", starID(t, "hoop33/limo"))
+
+	var star model.Star
+	assert.Equal(t, http.StatusOK, do(t, s, "POST", path, `{"name":"cli"}`, &star))
+
+	ops, err := model.FindUndoableOperations(db, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ops))
+	assert.Equal(t, "POST "+path, ops[0].Command)
+
+	_, err = ops[0].Undo(db)
+	assert.Nil(t, err)
+	assert.Nil(t, star.LoadTags(db))
+	assert.Equal(t, 0, len(star.Tags))
+}
+
+func TestServerShouldReturnStarDetail(t *testing.T) {
+	s := setUp(t)
+
+	var star model.Star
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", fmt.Sprintf("/api/stars/%d", starID(t, "spf13/cobra")), "", &star))
+	assert.Equal(t, "spf13/cobra", *star.FullName)
+}
+
+func TestServerShouldReturnNotFoundForMissingStar(t *testing.T) {
+	s := setUp(t)
+
+	var body map[string]string
+	assert.Equal(t, http.StatusNotFound, do(t, s, "GET", "/api/stars/999999", "", &body))
+	assert.Equal(t, "not found", body["error"])
+}
+
+func TestServerShouldReturnBadRequestForInvalidID(t *testing.T) {
+	s := setUp(t)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "GET", "/api/stars/abc", "", nil))
+}
+
+func TestServerShouldReturnNotFoundForUnknownRoute(t *testing.T) {
+	s := setUp(t)
+
+	assert.Equal(t, http.StatusNotFound, do(t, s, "PATCH", "/api/stars", "", nil))
+	assert.Equal(t, http.StatusNotFound, do(t, s, "GET", "/api/nope", "", nil))
+}
+
+func TestServerShouldCreateRenameAndDeleteTags(t *testing.T) {
+	s := setUp(t)
+
+	var tag model.Tag
+	assert.Equal(t, http.StatusCreated, do(t, s, "POST", "/api/tags", `{"name":"cli"}`, &tag))
+	assert.Equal(t, "cli", tag.Name)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "POST", "/api/tags", `{"name":"CLI"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "POST", "/api/tags", `{}`, nil))
+
+	path := fmt.Sprintf("/api/tags/%d", tag.ID)
+	assert.Equal(t, http.StatusOK, do(t, s, "PUT", path, `{"name":"terminal"}`, &tag))
+	assert.Equal(t, "terminal", tag.Name)
+
+	var tags []model.Tag
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/tags", "", &tags))
+	assert.Equal(t, 1, len(tags))
+	assert.Equal(t, "terminal", tags[0].Name)
+
+	assert.Equal(t, http.StatusNoContent, do(t, s, "DELETE", path, "", nil))
+	assert.Equal(t, http.StatusNotFound, do(t, s, "GET", path, "", nil))
+}
+
+func TestServerShouldRejectInvalidTagChanges(t *testing.T) {
+	s := setUp(t)
+	starPath := fmt.Sprintf("/api/stars/%d/tags", starID(t, "hoop33/limo"))
+
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "POST", starPath, `{"name":" / "}`, nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "POST", "/api/bulk/tags", `{"stars":[],"add":[""]}`, nil))
+	assert.Equal(t, http.StatusNotFound, do(t, s, "POST", "/api/stars/9999/tags", `{"name":"cli"}`, nil))
+
+	var cli, vim model.Tag
+	assert.Equal(t, http.StatusCreated, do(t, s, "POST", "/api/tags", `{"name":"cli"}`, &cli))
+	assert.Equal(t, http.StatusCreated, do(t, s, "POST", "/api/tags", `{"name":"vim"}`, &vim))
+
+	path := fmt.Sprintf("/api/tags/%d", cli.ID)
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "PUT", path, `{"name":"cli"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "PUT", path, `{"name":"vim"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "PUT", path, `{"name":""}`, nil))
+}
+
+func TestServerShouldListLanguages(t *testing.T) {
+	s := setUp(t)
+
+	var languages []string
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/languages", "", &languages))
+	assert.Equal(t, []string{"Go", "VimL"}, languages)
+}
+
+func TestServerShouldSearch(t *testing.T) {
+	s := setUp(t)
+
+	var hits []Hit
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/search?q=cobra", "", &hits))
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, "spf13/cobra", *hits[0].Star.FullName)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "GET", "/api/search", "", nil))
+}
+
+func TestServerShouldRejectUpdateWithoutUpdater(t *testing.T) {
+	s := setUp(t)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "POST", "/api/update", "{}", nil))
+}
+
+func TestServerShouldUpdate(t *testing.T) {
+	setUp(t)
+
+	serviceName := ""
+	s := New(db, index, func(ctx context.Context, name string) (int, int, int, error) {
+		serviceName = name
+		return 1, 2, 3, nil
+	}, testAddr)
+
+	var result UpdateResult
+	assert.Equal(t, http.StatusOK, do(t, s, "POST", "/api/update", `{"service":"gitlab"}`, &result))
+	assert.Equal(t, "gitlab", serviceName)
+	assert.Equal(t, UpdateResult{Created: 1, Updated: 2, Errors: 3}, result)
+}
+
+func TestServerShouldListServicesAndFilterByService(t *testing.T) {
+	s := setUp(t)
+
+	var services []model.Service
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/services", "", &services))
+	assert.Equal(t, 1, len(services))
+	assert.Equal(t, "github", services[0].Name)
+
+	var stars []model.Star
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/stars?service=github", "", &stars))
+	assert.Equal(t, 3, len(stars))
+
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/stars?service=gitlab", "", &stars))
+	assert.Equal(t, 0, len(stars))
+}
+
+func TestServerShouldSearchByPrefix(t *testing.T) {
+	s := setUp(t)
+
+	var hits []Hit
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/search?q=cob", "", &hits))
+	assert.Equal(t, 0, len(hits))
+
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/search?q=cob&prefix=true", "", &hits))
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, "spf13/cobra", *hits[0].Star.FullName)
+}
+
+func TestServerShouldBulkTagStars(t *testing.T) {
+	s := setUp(t)
+	limo, cobra := starID(t, "hoop33/limo"), starID(t, "spf13/cobra")
+
+	var stars []model.Star
+	body := fmt.Sprintf(`{"stars":[%d,%d],"add":["go","cli"]}`, limo, cobra)
+	assert.Equal(t, http.StatusOK, do(t, s, "POST", "/api/bulk/tags", body, &stars))
+	assert.Equal(t, 2, len(stars))
+	assert.Equal(t, 2, len(stars[0].Tags))
+	assert.Equal(t, 2, len(stars[1].Tags))
+
+	body = fmt.Sprintf(`{"stars":[%d],"remove":["cli","nope"]}`, cobra)
+	assert.Equal(t, http.StatusOK, do(t, s, "POST", "/api/bulk/tags", body, &stars))
+	assert.Equal(t, 1, len(stars[0].Tags))
+	assert.Equal(t, "go", stars[0].Tags[0].Name)
+
+	var hits []Hit
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/search?q=cli", "", &hits))
+	assert.Equal(t, 1, len(hits))
+	assert.Equal(t, "hoop33/limo", *hits[0].Star.FullName)
+}
+
+func TestServerShouldRejectBulkTagForMissingStar(t *testing.T) {
+	s := setUp(t)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, s, "POST", "/api/bulk/tags", `{"stars":[999999],"add":["go"]}`, nil))
+}
+
+func TestServerShouldRejectOtherHosts(t *testing.T) {
+	s := setUp(t)
+
+	for _, host := range []string{"evil.example.com:8080", "127.0.0.1:9090", "evil.example.com"} {
+		r := newRequest("GET", "/api/stars", "")
+		r.Host = host
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusForbidden, w.Code, host)
+	}
+
+	for _, host := range []string{"localhost:8080", "[::1]:8080"} {
+		r := newRequest("GET", "/api/stars", "")
+		r.Host = host
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code, host)
+	}
+}
+
+func TestServerShouldAllowAnyHostWhenListeningOnEveryAddress(t *testing.T) {
+	setUp(t)
+	s := New(db, index, nil, ":8080")
+
+	r := newRequest("GET", "/api/stars", "")
+	r.Host = "limo.example.com:8080"
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestServerShouldRejectChangesFromOtherOrigins(t *testing.T) {
+	s := setUp(t)
+
+	r := newRequest("POST", "/api/tags", `{"name":"vim"}`)
+	r.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	r = newRequest("DELETE", "/api/tags/1", "")
+	r.Header.Set("Origin", "null")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	r = newRequest("POST", "/api/tags", `{"name":"vim"}`)
+	r.Header.Set("Origin", "http://localhost:8080")
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestServerShouldRejectChangesThatArentJSON(t *testing.T) {
+	s := setUp(t)
+
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		r := newRequest("POST", "/api/tags", `{"name":"vim"}`)
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code, contentType)
+	}
+
+	var tags []model.Tag
+	assert.Equal(t, http.StatusOK, do(t, s, "GET", "/api/tags", "", &tags))
+	assert.Equal(t, 0, len(tags))
+}
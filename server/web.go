@@ -0,0 +1,323 @@
+package server
+
+import (
+	"net/http"
+)
+
+// Web serves the web UI, and the API at /api/
+func Web(api http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api/", api)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		// A failed write means the browser went away, so there's no one to tell
+		_, _ = w.Write([]byte(page))
+	})
+	return mux
+}
+
+// page is the web UI, kept inline so it's built into the binary and works offline
+const page = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>limo</title>
+<style>
+* { box-sizing: border-box; }
+body { margin: 0; font: 14px -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292e; display: flex; flex-direction: column; height: 100vh; }
+header { display: flex; align-items: center; gap: 12px; padding: 10px 16px; background: #24292e; color: #fff; }
+header h1 { margin: 0; font-size: 18px; }
+header input { flex: 1; padding: 6px 10px; font-size: 14px; border: 0; border-radius: 4px; }
+button { padding: 5px 10px; font-size: 13px; border: 1px solid #d1d5da; border-radius: 4px; background: #fafbfc; cursor: pointer; }
+main { display: flex; flex: 1; overflow: hidden; }
+nav { width: 240px; overflow-y: auto; padding: 8px 16px; border-right: 1px solid #e1e4e8; background: #f6f8fa; }
+nav h2 { font-size: 12px; text-transform: uppercase; color: #586069; margin: 16px 0 6px; }
+nav li { display: flex; justify-content: space-between; padding: 2px 6px; border-radius: 3px; cursor: pointer; }
+nav li:hover, nav li.drop { background: #dbedff; }
+nav li.active { background: #0366d6; color: #fff; }
+ul { list-style: none; margin: 0; padding: 0; }
+section { flex: 1; display: flex; flex-direction: column; overflow: hidden; }
+.toolbar { display: flex; align-items: center; gap: 8px; padding: 8px 16px; border-bottom: 1px solid #e1e4e8; }
+.toolbar input[type=text] { padding: 4px 8px; font-size: 13px; }
+.status { margin-left: auto; color: #586069; }
+#stars { flex: 1; overflow-y: auto; }
+.star { display: flex; gap: 10px; padding: 8px 16px; border-bottom: 1px solid #eaecef; }
+.star.selected { background: #fffbdd; }
+.star .body { flex: 1; min-width: 0; }
+.star a { font-weight: 600; color: #0366d6; text-decoration: none; }
+.star .meta { color: #586069; font-size: 12px; margin-left: 8px; }
+.star p { margin: 4px 0; color: #586069; }
+.chip { display: inline-block; margin: 2px 4px 0 0; padding: 0 8px; border-radius: 10px; background: #e1ecf4; font-size: 12px; }
+.chip span { margin-left: 4px; cursor: pointer; color: #586069; }
+#error { display: none; padding: 8px 16px; background: #ffdce0; color: #86181d; }
+</style>
+</head>
+<body>
+<header>
+  <h1>limo</h1>
+  <input id="search" type="search" placeholder="Search stars" autofocus>
+  <button id="update">Update</button>
+</header>
+<div id="error"></div>
+<main>
+  <nav>
+    <h2>Services</h2><ul id="services"></ul>
+    <h2>Languages</h2><ul id="languages"></ul>
+    <h2>Tags</h2><ul id="tags"></ul>
+    <p><input id="new-tag" type="text" placeholder="New tag"></p>
+  </nav>
+  <section>
+    <div class="toolbar">
+      <input id="select-all" type="checkbox" title="Select all">
+      <input id="bulk-tag" type="text" placeholder="Tag">
+      <button id="bulk-add">Add to selected</button>
+      <button id="bulk-remove">Remove from selected</button>
+      <span class="status" id="status"></span>
+    </div>
+    <div id="stars"></div>
+  </section>
+</main>
+<script>
+"use strict";
+
+const untagged = "(untagged)";
+const state = {
+  stars: [],
+  tags: [],
+  services: {},
+  filters: { service: "", language: "", tag: "" },
+  hits: null,
+  selected: new Set()
+};
+
+function $(id) {
+  return document.getElementById(id);
+}
+
+function el(tag, className, text) {
+  const e = document.createElement(tag);
+  if (className) e.className = className;
+  if (text !== undefined) e.textContent = text;
+  return e;
+}
+
+function api(method, path, body) {
+  $("error").style.display = "none";
+  const options = { method: method, headers: {} };
+  if (body !== undefined) {
+    options.headers["Content-Type"] = "application/json";
+    options.body = JSON.stringify(body);
+  }
+  return fetch("/api/" + path, options).then(function (response) {
+    if (response.status === 204) return null;
+    return response.json().then(function (data) {
+      if (!response.ok) throw new Error(data.error);
+      return data;
+    });
+  }).catch(function (err) {
+    $("error").textContent = err.message;
+    $("error").style.display = "block";
+    throw err;
+  });
+}
+
+function load() {
+  return Promise.all([api("GET", "stars"), api("GET", "tags"), api("GET", "services")]).then(function (results) {
+    state.stars = results[0] || [];
+    state.tags = results[1] || [];
+    state.services = {};
+    (results[2] || []).forEach(function (service) {
+      state.services[service.ID] = service.Name;
+    });
+    render();
+  });
+}
+
+function replaceStars(updated) {
+  updated.forEach(function (star) {
+    const i = state.stars.findIndex(function (s) { return s.ID === star.ID; });
+    if (i !== -1) state.stars[i] = star;
+  });
+  return api("GET", "tags").then(function (tags) {
+    state.tags = tags || [];
+    render();
+  });
+}
+
+function tagStars(ids, add, remove) {
+  if (ids.length === 0) return;
+  api("POST", "bulk/tags", { Stars: ids, Add: add, Remove: remove }).then(replaceStars);
+}
+
+function tagNames(star) {
+  const names = (star.Tags || []).map(function (tag) { return tag.Name; });
+  return names.length ? names : [untagged];
+}
+
+function facets(star) {
+  return {
+    service: [state.services[star.ServiceID] || ""],
+    language: [star.Language || ""],
+    tag: tagNames(star)
+  };
+}
+
+function matches(star, except) {
+  if (state.hits && !state.hits.has(star.ID)) return false;
+  const values = facets(star);
+  return Object.keys(state.filters).every(function (name) {
+    const filter = state.filters[name];
+    return name === except || !filter || values[name].indexOf(filter) !== -1;
+  });
+}
+
+function renderFacet(name, listID, dropTarget) {
+  const counts = {};
+  state.stars.forEach(function (star) {
+    if (!matches(star, name)) return;
+    facets(star)[name].forEach(function (value) {
+      if (value) counts[value] = (counts[value] || 0) + 1;
+    });
+  });
+  if (name === "tag") {
+    state.tags.forEach(function (tag) {
+      counts[tag.Name] = counts[tag.Name] || 0;
+    });
+  }
+
+  const list = $(listID);
+  list.textContent = "";
+  Object.keys(counts).sort().forEach(function (value) {
+    const li = el("li", state.filters[name] === value ? "active" : "");
+    li.appendChild(el("span", "", value));
+    li.appendChild(el("span", "", counts[value]));
+    li.onclick = function () {
+      state.filters[name] = state.filters[name] === value ? "" : value;
+      render();
+    };
+    if (dropTarget && value !== untagged) {
+      li.ondragover = function (e) { e.preventDefault(); li.classList.add("drop"); };
+      li.ondragleave = function () { li.classList.remove("drop"); };
+      li.ondrop = function (e) {
+        e.preventDefault();
+        li.classList.remove("drop");
+        tagStars(JSON.parse(e.dataTransfer.getData("text/plain")), [value], []);
+      };
+    }
+    list.appendChild(li);
+  });
+}
+
+function renderStar(star) {
+  const row = el("div", state.selected.has(star.ID) ? "star selected" : "star");
+  row.draggable = true;
+  row.ondragstart = function (e) {
+    const ids = state.selected.has(star.ID) ? Array.from(state.selected) : [star.ID];
+    e.dataTransfer.setData("text/plain", JSON.stringify(ids));
+  };
+
+  const check = el("input");
+  check.type = "checkbox";
+  check.checked = state.selected.has(star.ID);
+  check.onchange = function () {
+    if (check.checked) state.selected.add(star.ID); else state.selected.delete(star.ID);
+    render();
+  };
+  row.appendChild(check);
+
+  const body = el("div", "body");
+  const link = el("a", "", star.FullName || star.Name || "");
+  link.href = star.URL || "#";
+  link.target = "_blank";
+  link.rel = "noopener";
+  body.appendChild(link);
+  body.appendChild(el("span", "meta", "★ " + star.Stargazers + (star.Language ? " · " + star.Language : "")));
+  if (star.Description) body.appendChild(el("p", "", star.Description));
+  (star.Tags || []).forEach(function (tag) {
+    const chip = el("span", "chip", tag.Name);
+    const remove = el("span", "", "×");
+    remove.title = "Remove tag";
+    remove.onclick = function () { tagStars([star.ID], [], [tag.Name]); };
+    chip.appendChild(remove);
+    body.appendChild(chip);
+  });
+  row.appendChild(body);
+  return row;
+}
+
+function render() {
+  renderFacet("service", "services", false);
+  renderFacet("language", "languages", false);
+  renderFacet("tag", "tags", true);
+
+  const visible = state.stars.filter(function (star) { return matches(star); });
+  const list = $("stars");
+  list.textContent = "";
+  visible.forEach(function (star) { list.appendChild(renderStar(star)); });
+
+  $("select-all").checked = visible.length > 0 && visible.every(function (star) { return state.selected.has(star.ID); });
+  $("status").textContent = visible.length + " of " + state.stars.length + " stars" +
+    (state.selected.size ? ", " + state.selected.size + " selected" : "");
+}
+
+let searchTimer = null;
+let searchSequence = 0;
+$("search").oninput = function () {
+  clearTimeout(searchTimer);
+  searchTimer = setTimeout(function () {
+    const q = $("search").value.trim();
+    const sequence = ++searchSequence;
+    if (!q) {
+      state.hits = null;
+      render();
+      return;
+    }
+    api("GET", "search?prefix=true&q=" + encodeURIComponent(q)).then(function (hits) {
+      if (sequence !== searchSequence) return;
+      state.hits = new Set((hits || []).map(function (hit) { return hit.Star.ID; }));
+      render();
+    });
+  }, 150);
+};
+
+$("select-all").onchange = function () {
+  state.stars.filter(function (star) { return matches(star); }).forEach(function (star) {
+    if ($("select-all").checked) state.selected.add(star.ID); else state.selected.delete(star.ID);
+  });
+  render();
+};
+
+function bulk(add) {
+  const name = $("bulk-tag").value.trim();
+  if (!name) return;
+  tagStars(Array.from(state.selected), add ? [name] : [], add ? [] : [name]);
+}
+$("bulk-add").onclick = function () { bulk(true); };
+$("bulk-remove").onclick = function () { bulk(false); };
+
+$("new-tag").onkeydown = function (e) {
+  const name = $("new-tag").value.trim();
+  if (e.key !== "Enter" || !name) return;
+  api("POST", "tags", { Name: name }).then(function () {
+    $("new-tag").value = "";
+    return replaceStars([]);
+  });
+};
+
+$("update").onclick = function () {
+  $("update").disabled = true;
+  $("status").textContent = "Updating…";
+  api("POST", "update", {}).then(load).finally(function () {
+    $("update").disabled = false;
+  });
+};
+
+load();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hoop33/limo/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebShouldServePage(t *testing.T) {
+	w := httptest.NewRecorder()
+	Web(setUp(t)).ServeHTTP(w, newRequest("GET", "/", ""))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.True(t, strings.Contains(w.Body.String(), "<title>limo</title>"))
+}
+
+func TestWebShouldNotReferenceRemoteAssets(t *testing.T) {
+	assert.False(t, strings.Contains(page, "http://"))
+	assert.False(t, strings.Contains(page, "https://"))
+}
+
+func TestWebShouldReturnNotFoundForUnknownPath(t *testing.T) {
+	w := httptest.NewRecorder()
+	Web(setUp(t)).ServeHTTP(w, newRequest("GET", "/nope", ""))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebShouldServeAPI(t *testing.T) {
+	w := httptest.NewRecorder()
+	Web(setUp(t)).ServeHTTP(w, newRequest("GET", "/api/languages", ""))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "[\"Go\",\"VimL\"]\n", w.Body.String())
+}
+
+func TestWebShouldRejectBulkTaggingFromOtherOrigins(t *testing.T) {
+	handler := Web(setUp(t))
+
+	r := newRequest("POST", "/api/bulk/tags", `{"stars":[1],"add":["vim"]}`)
+	r.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	r = newRequest("POST", "/api/bulk/tags", `{"stars":[1],"add":["vim"]}`)
+	r.Header.Set("Content-Type", "text/plain")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	tags, err := model.FindTags(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(tags))
+}
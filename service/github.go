@@ -1,8 +1,15 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -12,8 +19,67 @@ import (
 	"github.com/hoop33/limo/model"
 )
 
+const githubGraphQLURL = "https://api.github.com/graphql"
+
+const listsQuery = `query($cursor: String) {
+  viewer {
+    lists(first: 100, after: $cursor) {
+      nodes {
+        id
+        name
+        items(first: 100) {
+          nodes { ... on Repository { databaseId } }
+          pageInfo { hasNextPage endCursor }
+        }
+      }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+const listItemsQuery = `query($id: ID!, $cursor: String) {
+  node(id: $id) {
+    ... on UserList {
+      items(first: 100, after: $cursor) {
+        nodes { ... on Repository { databaseId } }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+const createListMutation = `mutation($name: String!) {
+  createUserList(input: {name: $name}) {
+    list { id name }
+  }
+}`
+
+const repositoryIDQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) { id }
+}`
+
+const updateListsMutation = `mutation($itemId: ID!, $listIds: [ID!]!) {
+  updateUserListsForItem(input: {itemId: $itemId, listIds: $listIds}) {
+    lists { id }
+  }
+}`
+
 // Github represents the Github service
 type Github struct {
+	graphQLURL string
+	baseURL    string
+}
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLListItems struct {
+	Nodes []struct {
+		DatabaseID int64 `json:"databaseId"`
+	} `json:"nodes"`
+	PageInfo graphQLPageInfo `json:"pageInfo"`
 }
 
 // Login logs in to Github
@@ -144,70 +210,253 @@ func (g *Github) GetEvents(ctx context.Context, eventChan chan<- *model.EventRes
 }
 
 // GetTrending returns the trending repositories
-func (g *Github) GetTrending(ctx context.Context, trendingChan chan<- *model.StarResult, token string, language string, verbose bool) {
+func (g *Github) GetTrending(ctx context.Context, trendingChan chan<- *model.StarResult, token string, opts TrendingOptions) {
+	defer close(trendingChan)
+
 	client := g.getClient(token)
 
-	// TODO perhaps allow them to specify multiple pages?
-	// Might be overkill -- first page probably plenty
+	q := g.getTrendingSearchString(opts)
+	if opts.Verbose {
+		fmt.Println("q =", q)
+	}
 
-	// TODO Make this more configurable. Sort by stars, forks, default.
-	// Search by number of stars, pushed, created, or whatever.
-	// Lots of possibilities.
+	currentPage := 1
+	lastPage := opts.Pages
 
-	q := g.getDateSearchString()
+	for currentPage <= lastPage {
+		result, response, err := client.Search.Repositories(ctx, q, &github.SearchOptions{
+			Sort:  opts.Sort,
+			Order: "desc",
+			ListOptions: github.ListOptions{
+				Page: currentPage,
+			},
+		})
 
-	if language != "" {
-		q = fmt.Sprintf("language:%s %s", language, q)
-	}
+		// If we got an error, put it on the channel
+		if err != nil {
+			trendingChan <- &model.StarResult{
+				Error: err,
+				Star:  nil,
+			}
+		} else {
+			// Stop early if there are fewer pages than asked for
+			if response.NextPage == 0 {
+				lastPage = currentPage
+			}
 
-	if verbose {
-		fmt.Println("q =", q)
+			// Create a Star for each repository and put it on the channel
+			for _, repo := range result.Repositories {
+				star, err := model.NewStarFromGithub(nil, repo)
+				trendingChan <- &model.StarResult{
+					Error: err,
+					Star:  star,
+				}
+			}
+		}
+		currentPage++
 	}
+}
 
-	result, _, err := client.Search.Repositories(ctx, q, &github.SearchOptions{
-		Sort:  "stars",
-		Order: "desc",
-	})
+// GetLists returns the star lists for the authenticated user
+func (g *Github) GetLists(ctx context.Context, token string) ([]*model.List, error) {
+	var lists []*model.List
 
-	// If we got an error, put it on the channel
-	if err != nil {
-		trendingChan <- &model.StarResult{
-			Error: err,
-			Star:  nil,
+	variables := map[string]interface{}{
+		"cursor": nil,
+	}
+
+	for {
+		var result struct {
+			Viewer struct {
+				Lists struct {
+					Nodes []struct {
+						ID    string           `json:"id"`
+						Name  string           `json:"name"`
+						Items graphQLListItems `json:"items"`
+					} `json:"nodes"`
+					PageInfo graphQLPageInfo `json:"pageInfo"`
+				} `json:"lists"`
+			} `json:"viewer"`
 		}
-	} else {
-		// Create a Star for each repository and put it on the channel
-		for _, repo := range result.Repositories {
-			star, err := model.NewStarFromGithub(nil, repo)
-			trendingChan <- &model.StarResult{
-				Error: err,
-				Star:  star,
+		if err := g.query(ctx, token, listsQuery, variables, &result); err != nil {
+			return nil, err
+		}
+
+		for _, node := range result.Viewer.Lists.Nodes {
+			list := &model.List{
+				RemoteID: node.ID,
+				Name:     node.Name,
 			}
+
+			// Lists can hold more items than fit on one page
+			items := node.Items
+			for {
+				for _, item := range items.Nodes {
+					if item.DatabaseID != 0 {
+						list.StarRemoteIDs = append(list.StarRemoteIDs, strconv.FormatInt(item.DatabaseID, 10))
+					}
+				}
+				if !items.PageInfo.HasNextPage {
+					break
+				}
+				var err error
+				if items, err = g.getListItems(ctx, token, node.ID, items.PageInfo.EndCursor); err != nil {
+					return nil, err
+				}
+			}
+			lists = append(lists, list)
+		}
+
+		if !result.Viewer.Lists.PageInfo.HasNextPage {
+			break
 		}
+		variables["cursor"] = result.Viewer.Lists.PageInfo.EndCursor
+	}
+	return lists, nil
+}
+
+// CreateList creates a star list
+func (g *Github) CreateList(ctx context.Context, token, name string) (*model.List, error) {
+	var result struct {
+		CreateUserList struct {
+			List struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"list"`
+		} `json:"createUserList"`
+	}
+	if err := g.query(ctx, token, createListMutation, map[string]interface{}{
+		"name": name,
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &model.List{
+		RemoteID: result.CreateUserList.List.ID,
+		Name:     result.CreateUserList.List.Name,
+	}, nil
+}
+
+// SetStarLists sets the star lists a star belongs to
+func (g *Github) SetStarLists(ctx context.Context, token string, star *model.Star, listIDs []string) error {
+	if star.FullName == nil {
+		return errors.New("star has no full name")
+	}
+	parts := strings.SplitN(*star.FullName, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("'%s' is not owner/repo", *star.FullName)
+	}
+
+	var repo struct {
+		Repository struct {
+			ID string `json:"id"`
+		} `json:"repository"`
+	}
+	if err := g.query(ctx, token, repositoryIDQuery, map[string]interface{}{
+		"owner": parts[0],
+		"name":  parts[1],
+	}, &repo); err != nil {
+		return err
 	}
 
-	close(trendingChan)
+	// An empty slice, not null, removes the star from all lists
+	if listIDs == nil {
+		listIDs = []string{}
+	}
+	var result json.RawMessage
+	return g.query(ctx, token, updateListsMutation, map[string]interface{}{
+		"itemId":  repo.Repository.ID,
+		"listIds": listIDs,
+	}, &result)
 }
 
 // SetInsecure sets whether to skip cert verification
 func (g *Github) SetInsecure(insecure bool) {
 }
 
-func (g *Github) getDateSearchString() string {
-	// TODO make this configurable
-	// Default should be in configuration file
-	// and should be able to override from command line
-	// TODO should be able to specify whether "created" or "pushed"
-	date := time.Now().Add(-7 * (24 * time.Hour))
-	return fmt.Sprintf("created:>%s", date.Format("2006-01-02"))
+func (g *Github) getListItems(ctx context.Context, token, listID, cursor string) (graphQLListItems, error) {
+	var result struct {
+		Node struct {
+			Items graphQLListItems `json:"items"`
+		} `json:"node"`
+	}
+	err := g.query(ctx, token, listItemsQuery, map[string]interface{}{
+		"id":     listID,
+		"cursor": cursor,
+	}, &result)
+	return result.Node.Items, err
+}
+
+func (g *Github) query(ctx context.Context, token, query string, variables map[string]interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := g.graphQLURL
+	if url == "" {
+		url = githubGraphQLURL
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.getHTTPClient(token).Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("POST %s: %s", url, resp.Status)
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	if len(envelope.Errors) > 0 {
+		return errors.New(envelope.Errors[0].Message)
+	}
+	return json.Unmarshal(envelope.Data, result)
+}
+
+func (g *Github) getTrendingSearchString(opts TrendingOptions) string {
+	q := fmt.Sprintf("%s:>%s", opts.By, opts.Since.Format("2006-01-02"))
+	if opts.MinStars > 0 {
+		q = fmt.Sprintf("%s stars:>=%d", q, opts.MinStars)
+	}
+	if opts.Language != "" {
+		q = fmt.Sprintf("language:%s %s", opts.Language, q)
+	}
+	return q
 }
 
 func (g *Github) getClient(token string) *github.Client {
+	client := github.NewClient(g.getHTTPClient(token))
+	if g.baseURL != "" {
+		client.BaseURL, _ = url.Parse(g.baseURL + "/")
+	}
+	return client
+}
+
+func (g *Github) getHTTPClient(token string) *http.Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(context.Background(), ts)
-	return github.NewClient(tc)
+	return oauth2.NewClient(context.Background(), ts)
 }
 
 func init() {
@@ -1 +1,121 @@
 package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hoop33/limo/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGithubGetListsShouldPageThroughItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query string `json:"query"`
+		}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		if strings.Contains(body.Query, "viewer") {
+			_, _ = w.Write([]byte(`{"data":{"viewer":{"lists":{
+				"nodes":[{"id":"L1","name":"cli","items":{
+					"nodes":[{"databaseId":33}],
+					"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}],
+				"pageInfo":{"hasNextPage":false}}}}}`))
+		} else {
+			_, _ = w.Write([]byte(`{"data":{"node":{"items":{
+				"nodes":[{"databaseId":34}],
+				"pageInfo":{"hasNextPage":false}}}}}`))
+		}
+	}))
+	defer server.Close()
+
+	g := &Github{graphQLURL: server.URL}
+	lists, err := g.GetLists(context.Background(), "token")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(lists))
+	assert.Equal(t, "L1", lists[0].RemoteID)
+	assert.Equal(t, "cli", lists[0].Name)
+	assert.Equal(t, []string{"33", "34"}, lists[0].StarRemoteIDs)
+}
+
+func TestGithubSetStarListsShouldSendEmptyListIDs(t *testing.T) {
+	var variables map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		if strings.Contains(body.Query, "repository(") {
+			_, _ = w.Write([]byte(`{"data":{"repository":{"id":"R1"}}}`))
+		} else {
+			variables = body.Variables
+			_, _ = w.Write([]byte(`{"data":{"updateUserListsForItem":{"lists":[]}}}`))
+		}
+	}))
+	defer server.Close()
+
+	fullName := "hoop33/limo"
+	g := &Github{graphQLURL: server.URL}
+	err := g.SetStarLists(context.Background(), "token", &model.Star{FullName: &fullName}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "R1", variables["itemId"])
+	assert.Equal(t, []interface{}{}, variables["listIds"])
+}
+
+func TestGithubQueryShouldReturnGraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"boom"}]}`))
+	}))
+	defer server.Close()
+
+	g := &Github{graphQLURL: server.URL}
+	_, err := g.CreateList(context.Background(), "token", "cli")
+	assert.NotNil(t, err)
+	assert.Equal(t, "boom", err.Error())
+}
+
+func TestGithubGetTrendingSearchStringShouldUseOptions(t *testing.T) {
+	g := &Github{}
+	since := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "created:>2017-06-01", g.getTrendingSearchString(TrendingOptions{Since: since, By: "created"}))
+	assert.Equal(t, "language:go pushed:>2017-06-01 stars:>=100",
+		g.getTrendingSearchString(TrendingOptions{Language: "go", Since: since, By: "pushed", MinStars: 100}))
+}
+
+func TestGithubGetTrendingShouldStopAtTheLastPage(t *testing.T) {
+	var pages []string
+	var sort string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		sort = r.URL.Query().Get("sort")
+		if page == "1" {
+			w.Header().Set("Link", `<`+r.URL.Path+`?page=2>; rel="next", <`+r.URL.Path+`?page=2>; rel="last"`)
+		}
+		_, _ = w.Write([]byte(`{"items":[{"id":` + page + `,"full_name":"hoop33/limo"}]}`))
+	}))
+	defer server.Close()
+
+	trendingChan := make(chan *model.StarResult, 20)
+	g := &Github{baseURL: server.URL}
+	go g.GetTrending(context.Background(), trendingChan, "token", TrendingOptions{
+		Since: time.Now(),
+		By:    "created",
+		Sort:  "forks",
+		Pages: 3,
+	})
+
+	var remoteIDs []string
+	for starResult := range trendingChan {
+		assert.Nil(t, starResult.Error)
+		remoteIDs = append(remoteIDs, starResult.Star.RemoteID)
+	}
+	assert.Equal(t, []string{"1", "2"}, pages)
+	assert.Equal(t, "forks", sort)
+	assert.Equal(t, []string{"1", "2"}, remoteIDs)
+}
@@ -3,9 +3,10 @@ package service
 import (
 	"context"
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hoop33/entrevista"
@@ -16,6 +17,17 @@ import (
 // Gitlab represents the Gitlab service
 type Gitlab struct {
 	insecure bool
+	baseURL  string
+}
+
+// gitlabTrendingOptions are the options for listing trending projects, which
+// go-gitlab's ListProjectsOptions doesn't cover
+type gitlabTrendingOptions struct {
+	gitlab.ListOptions
+	OrderBy           string                 `url:"order_by,omitempty"`
+	Sort              string                 `url:"sort,omitempty"`
+	Visibility        gitlab.VisibilityValue `url:"visibility,omitempty"`
+	LastActivityAfter *time.Time             `url:"last_activity_after,omitempty"`
 }
 
 // Login logs in to Gitlab
@@ -113,18 +125,191 @@ func (g *Gitlab) GetStars(ctx context.Context, starChan chan<- *model.StarResult
 	}
 }
 
-// GetEvents returns the events for the authenticated user
+// GetEvents returns the events for the specified user
 func (g *Gitlab) GetEvents(ctx context.Context, eventChan chan<- *model.EventResult, token, user string, page, count int) {
-	eventChan <- &model.EventResult{
-		Error: errors.New("GitLab not yet supported"),
-		Event: nil,
+	defer close(eventChan)
+
+	client := g.getClient(token)
+
+	// Events only carry project IDs, so look up each project once for its name
+	projects := make(map[int]*gitlab.Project)
+
+	currentPage := page
+	lastPage := page + count - 1
+
+	for currentPage <= lastPage {
+		events, _, err := client.Users.ListUserContributionEvents(user, &gitlab.ListContributionEventsOptions{
+			ListOptions: gitlab.ListOptions{
+				Page: currentPage,
+			},
+		})
+
+		if err != nil {
+			eventChan <- &model.EventResult{
+				Error: err,
+				Event: nil,
+			}
+		} else {
+			for _, event := range events {
+				project, ok := projects[event.ProjectID]
+				if !ok && event.ProjectID != 0 {
+					// If we can't find the project, the event just won't name it
+					project, _, _ = client.Projects.GetProject(event.ProjectID)
+					projects[event.ProjectID] = project
+				}
+				eventChan <- &model.EventResult{
+					Error: nil,
+					Event: model.NewEventFromGitlab(event, project),
+				}
+			}
+		}
+		currentPage++
 	}
-	close(eventChan)
 }
 
-// GetTrending returns the trending repositories
-func (g *Gitlab) GetTrending(ctx context.Context, trendingChan chan<- *model.StarResult, token string, language string, verbose bool) {
-	close(trendingChan)
+// gitlabTrendingOrders map trending sorts to the GitLab project orders
+var gitlabTrendingOrders = map[string]string{
+	"stars":   "star_count",
+	"updated": "last_activity_at",
+}
+
+// GetTrending returns the trending repositories. GitLab can't search projects by creation date, so for
+// projects created since then, this lists the newest public projects until it reaches older ones, then
+// sorts them itself
+func (g *Gitlab) GetTrending(ctx context.Context, trendingChan chan<- *model.StarResult, token string, opts TrendingOptions) {
+	defer close(trendingChan)
+
+	orderBy, ok := gitlabTrendingOrders[opts.Sort]
+	if !ok {
+		trendingChan <- &model.StarResult{
+			Error: fmt.Errorf("GitLab can't sort trending projects by %s", opts.Sort),
+			Star:  nil,
+		}
+		return
+	}
+	byCreated := opts.By == "created"
+	if byCreated {
+		orderBy = "created_at"
+	}
+
+	client := g.getClient(token)
+
+	if opts.Verbose {
+		fmt.Println("order_by =", orderBy, "last_activity_after =", opts.Since.Format("2006-01-02"))
+	}
+
+	var created []*gitlab.Project
+	currentPage := 1
+	lastPage := opts.Pages
+
+	for currentPage <= lastPage {
+		req, err := client.NewRequest("GET", "projects", &gitlabTrendingOptions{
+			ListOptions: gitlab.ListOptions{
+				Page: currentPage,
+				// Get full pages, since filtering can drop most of them
+				PerPage: 100,
+			},
+			OrderBy:           orderBy,
+			Sort:              "desc",
+			Visibility:        gitlab.PublicVisibility,
+			LastActivityAfter: &opts.Since,
+		}, nil)
+		var projects []*gitlab.Project
+		var response *gitlab.Response
+		if err == nil {
+			response, err = client.Do(req, &projects)
+		}
+
+		// If we got an error, put it on the channel
+		if err != nil {
+			trendingChan <- &model.StarResult{
+				Error: err,
+				Star:  nil,
+			}
+		} else {
+			// Stop early if there are fewer pages than asked for
+			if response.NextPage == 0 {
+				lastPage = currentPage
+			}
+
+			for _, project := range projects {
+				if byCreated && project.CreatedAt != nil && project.CreatedAt.Before(opts.Since) {
+					// The rest are older still
+					lastPage = currentPage
+					break
+				}
+				if project.StarCount < opts.MinStars {
+					continue
+				}
+				if byCreated {
+					created = append(created, project)
+				} else {
+					g.sendTrending(trendingChan, client, project, opts.Language)
+				}
+			}
+		}
+		currentPage++
+	}
+
+	sortGitlabTrending(created, opts.Sort)
+	for _, project := range created {
+		g.sendTrending(trendingChan, client, project, opts.Language)
+	}
+}
+
+// sortGitlabTrending sorts projects, most first, the way GitLab would for a trending sort
+func sortGitlabTrending(projects []*gitlab.Project, by string) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		if by == "updated" {
+			if projects[i].LastActivityAt == nil || projects[j].LastActivityAt == nil {
+				return projects[j].LastActivityAt == nil && projects[i].LastActivityAt != nil
+			}
+			return projects[i].LastActivityAt.After(*projects[j].LastActivityAt)
+		}
+		return projects[i].StarCount > projects[j].StarCount
+	})
+}
+
+// sendTrending creates a Star for a project and puts it on the channel. Projects don't have a language,
+// so if we're filtering by language, it uses the language most of the project's code is in
+func (g *Gitlab) sendTrending(trendingChan chan<- *model.StarResult, client *gitlab.Client, project *gitlab.Project, language string) {
+	star, err := model.NewStarFromGitlab(*project)
+	if err == nil && language != "" {
+		var projectLanguage string
+		if projectLanguage, err = g.getMainLanguage(client, project.ID); err == nil {
+			if !strings.EqualFold(projectLanguage, language) {
+				return
+			}
+			star.Language = &projectLanguage
+		}
+	}
+	trendingChan <- &model.StarResult{
+		Error: err,
+		Star:  star,
+	}
+}
+
+// getMainLanguage returns the language most of a project's code is in, or an empty string if it has no code
+func (g *Gitlab) getMainLanguage(client *gitlab.Client, projectID int) (string, error) {
+	req, err := client.NewRequest("GET", fmt.Sprintf("projects/%d/languages", projectID), nil, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Languages are a map of language names to the percentage of the code in each
+	var languages map[string]float64
+	if _, err := client.Do(req, &languages); err != nil {
+		return "", err
+	}
+
+	mainLanguage := ""
+	for name, percent := range languages {
+		if mainLanguage == "" || percent > languages[mainLanguage] ||
+			(percent == languages[mainLanguage] && name < mainLanguage) {
+			mainLanguage = name
+		}
+	}
+	return mainLanguage, nil
 }
 
 // SetInsecure sets whether to skip cert verification
@@ -142,7 +327,11 @@ func (g *Gitlab) getClient(token string) *gitlab.Client {
 			},
 		},
 	}
-	return gitlab.NewClient(client, token)
+	gitlabClient := gitlab.NewClient(client, token)
+	if g.baseURL != "" {
+		_ = gitlabClient.SetBaseURL(g.baseURL)
+	}
+	return gitlabClient
 }
 
 func init() {
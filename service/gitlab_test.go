@@ -1 +1,168 @@
 package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hoop33/limo/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitlabGetEventsShouldGetEachPageAndNameProjects(t *testing.T) {
+	var pages []string
+	projectLookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/users/hoop33/events":
+			pages = append(pages, r.URL.Query().Get("page"))
+			_, _ = w.Write([]byte(`[
+				{"project_id":7,"action_name":"pushed to","author":{"username":"hoop33"},"created_at":"2017-06-01T12:00:00Z"},
+				{"project_id":7,"action_name":"opened","target_type":"MergeRequest","author_username":"hoop33"}]`))
+		case "/api/v4/projects/7":
+			projectLookups++
+			_, _ = w.Write([]byte(`{"id":7,"path_with_namespace":"hoop33/limo","web_url":"https://gitlab.com/hoop33/limo"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	eventChan := make(chan *model.EventResult, 20)
+	g := &Gitlab{baseURL: server.URL}
+	go g.GetEvents(context.Background(), eventChan, "token", "hoop33", 2, 2)
+
+	var events []*model.Event
+	for eventResult := range eventChan {
+		assert.Nil(t, eventResult.Error)
+		events = append(events, eventResult.Event)
+	}
+	assert.Equal(t, []string{"2", "3"}, pages)
+	assert.Equal(t, 1, projectLookups)
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, "hoop33", events[0].Who)
+	assert.Equal(t, "pushed to", events[0].What)
+	assert.Equal(t, "hoop33/limo", events[0].Which)
+	assert.Equal(t, "https://gitlab.com/hoop33/limo", events[0].URL)
+	assert.Equal(t, 2017, events[0].When.Year())
+	assert.Equal(t, "opened a merge request on", events[1].What)
+}
+
+func TestGitlabGetEventsShouldReportErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	eventChan := make(chan *model.EventResult, 20)
+	g := &Gitlab{baseURL: server.URL}
+	go g.GetEvents(context.Background(), eventChan, "token", "nobody", 1, 1)
+
+	eventResult := <-eventChan
+	assert.NotNil(t, eventResult.Error)
+	assert.Nil(t, eventResult.Event)
+	_, ok := <-eventChan
+	assert.False(t, ok)
+}
+
+func TestGitlabGetTrendingShouldFilterByMainLanguage(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/projects":
+			query = r.URL.Query()
+			_, _ = w.Write([]byte(`[
+				{"id":1,"name":"limo","name_with_namespace":"hoop33 / limo","star_count":50},
+				{"id":2,"name":"rails","name_with_namespace":"ruby / rails","star_count":40}]`))
+		case "/api/v4/projects/1/languages":
+			_, _ = w.Write([]byte(`{"Go":80.5,"Makefile":19.5}`))
+		case "/api/v4/projects/2/languages":
+			_, _ = w.Write([]byte(`{"Ruby":70,"Go":30}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	trendingChan := make(chan *model.StarResult, 20)
+	g := &Gitlab{baseURL: server.URL}
+	go g.GetTrending(context.Background(), trendingChan, "token", TrendingOptions{
+		Language: "go",
+		Since:    time.Now().AddDate(0, 0, -7),
+		By:       "pushed",
+		Sort:     "stars",
+		Pages:    1,
+	})
+
+	var stars []*model.Star
+	for starResult := range trendingChan {
+		assert.Nil(t, starResult.Error)
+		stars = append(stars, starResult.Star)
+	}
+	assert.Equal(t, []string{"star_count"}, query["order_by"])
+	assert.Equal(t, []string{"desc"}, query["sort"])
+	assert.Equal(t, 1, len(query["last_activity_after"]))
+	assert.Equal(t, 1, len(stars))
+	assert.Equal(t, "hoop33 / limo", *stars[0].FullName)
+	assert.Equal(t, "Go", *stars[0].Language)
+	assert.Equal(t, 50, stars[0].Stargazers)
+}
+
+func TestGitlabGetTrendingShouldListNewestProjectsAndSortThemForCreated(t *testing.T) {
+	var pages, orders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/projects" {
+			http.NotFound(w, r)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		orders = append(orders, r.URL.Query().Get("order_by"))
+		w.Header().Set("X-Next-Page", fmt.Sprintf("%d", len(pages)+1))
+		if page == "1" {
+			_, _ = w.Write([]byte(`[
+				{"id":1,"name_with_namespace":"new / popular","star_count":50,"created_at":"2099-01-02T00:00:00Z"},
+				{"id":2,"name_with_namespace":"new / unpopular","star_count":1,"created_at":"2099-01-01T00:00:00Z"}]`))
+		} else {
+			_, _ = w.Write([]byte(`[
+				{"id":3,"name_with_namespace":"newer / popular","star_count":80,"created_at":"2098-01-01T00:00:00Z"},
+				{"id":4,"name_with_namespace":"old / popular","star_count":90,"created_at":"2001-01-01T00:00:00Z"}]`))
+		}
+	}))
+	defer server.Close()
+
+	trendingChan := make(chan *model.StarResult, 20)
+	g := &Gitlab{baseURL: server.URL}
+	go g.GetTrending(context.Background(), trendingChan, "", TrendingOptions{
+		Since:    time.Now().AddDate(0, 0, -7),
+		By:       "created",
+		Sort:     "stars",
+		Pages:    5,
+		MinStars: 10,
+	})
+
+	var names []string
+	for starResult := range trendingChan {
+		assert.Nil(t, starResult.Error)
+		assert.Nil(t, starResult.Star.Language)
+		names = append(names, *starResult.Star.FullName)
+	}
+	// Stops at the first project created before --since
+	assert.Equal(t, []string{"1", "2"}, pages)
+	assert.Equal(t, []string{"created_at", "created_at"}, orders)
+	assert.Equal(t, []string{"newer / popular", "new / popular"}, names)
+}
+
+func TestGitlabGetTrendingShouldReportUnsupportedSorts(t *testing.T) {
+	trendingChan := make(chan *model.StarResult, 20)
+	g := &Gitlab{}
+	go g.GetTrending(context.Background(), trendingChan, "", TrendingOptions{Sort: "forks", Pages: 1})
+
+	starResult := <-trendingChan
+	assert.NotNil(t, starResult.Error)
+	_, ok := <-trendingChan
+	assert.False(t, ok)
+}
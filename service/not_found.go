@@ -33,7 +33,7 @@ func (nf *NotFound) GetStars(ctx context.Context, starChan chan<- *model.StarRes
 }
 
 // GetTrending is not implemented
-func (nf *NotFound) GetTrending(ctx context.Context, trendingChan chan<- *model.StarResult, token string, language string, verbose bool) {
+func (nf *NotFound) GetTrending(ctx context.Context, trendingChan chan<- *model.StarResult, token string, opts TrendingOptions) {
 }
 
 // GetEvents is not implemented
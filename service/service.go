@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/hoop33/entrevista"
@@ -20,11 +21,29 @@ type Service interface {
 	AddStar(ctx context.Context, token, owner, repo string) (*model.Star, error)
 	DeleteStar(ctx context.Context, token, owner, repo string) (*model.Star, error)
 	GetStars(ctx context.Context, starChan chan<- *model.StarResult, token, user string)
-	GetTrending(ctx context.Context, trendingChan chan<- *model.StarResult, token, language string, verbose bool)
+	GetTrending(ctx context.Context, trendingChan chan<- *model.StarResult, token string, opts TrendingOptions)
 	GetEvents(ctx context.Context, eventChan chan<- *model.EventResult, token, user string, page, count int)
 	SetInsecure(insecure bool)
 }
 
+// TrendingOptions are the options for finding trending repositories
+type TrendingOptions struct {
+	Language string
+	Since    time.Time
+	By       string // created or pushed since Since
+	Sort     string // stars, forks, or updated
+	Pages    int
+	MinStars int
+	Verbose  bool
+}
+
+// Lister represents a service that supports lists of stars
+type Lister interface {
+	GetLists(ctx context.Context, token string) ([]*model.List, error)
+	CreateList(ctx context.Context, token, name string) (*model.List, error)
+	SetStarLists(ctx context.Context, token string, star *model.Star, listIDs []string) error
+}
+
 var services = make(map[string]Service)
 
 func registerService(service Service) {
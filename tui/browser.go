@@ -0,0 +1,503 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve"
+	"github.com/hoop33/limo/model"
+	"github.com/jinzhu/gorm"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const maxHits = 500
+
+type view int
+
+const (
+	viewStars view = iota
+	viewTags
+	viewLanguages
+)
+
+var viewNames = map[view]string{
+	viewStars:     "Stars",
+	viewTags:      "Tags",
+	viewLanguages: "Languages",
+}
+
+const help = "Type to search  ↑/↓ Move  Tab View  Enter Select  ^T Tag  ^R Untag  ^O Open  Esc Back"
+
+type item struct {
+	label string
+	name  string
+	count int
+	star  *model.Star
+}
+
+// Browser is a full-screen terminal UI for browsing and tagging stars
+type Browser struct {
+	db       *gorm.DB
+	index    bleve.Index
+	stars    []*model.Star
+	byID     map[string]*model.Star
+	view     view
+	query    string
+	tag      string
+	language string
+	items    []item
+	selected int
+	offset   int
+	prompt   string
+	input    string
+	action   func(string)
+	status   string
+	quit     bool
+}
+
+// New creates a browser for the stars in the database
+func New(db *gorm.DB, index bleve.Index) (*Browser, error) {
+	stars, err := model.FindStars(db, "")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Browser{
+		db:    db,
+		index: index,
+		byID:  make(map[string]*model.Star),
+	}
+
+	for i := range stars {
+		star := &stars[i]
+		if err := star.LoadTags(db); err != nil {
+			return nil, err
+		}
+		b.stars = append(b.stars, star)
+		b.byID[strconv.Itoa(int(star.ID))] = star
+	}
+
+	b.refresh()
+	return b, nil
+}
+
+// Run runs the browser until the user quits
+func (b *Browser) Run(in, out *os.File) error {
+	fd := int(in.Fd())
+	if !terminal.IsTerminal(fd) {
+		return errors.New("browse requires a terminal")
+	}
+
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = terminal.Restore(fd, state)
+	}()
+
+	if _, err := out.WriteString(enterScreen); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = out.WriteString(exitScreen)
+	}()
+
+	buf := make([]byte, 64)
+	for !b.quit {
+		width, height, err := terminal.GetSize(int(out.Fd()))
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(b.render(width, height)); err != nil {
+			return err
+		}
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			b.handle(k, height)
+		}
+	}
+	return nil
+}
+
+func (b *Browser) handle(k key, height int) {
+	if b.prompt != "" {
+		b.handlePrompt(k)
+		return
+	}
+
+	b.status = ""
+	page := height - 4
+	if page < 1 {
+		page = 1
+	}
+
+	switch k.name {
+	case "":
+		b.query += string(k.r)
+		b.refresh()
+	case "backspace":
+		if b.query != "" {
+			runes := []rune(b.query)
+			b.query = string(runes[:len(runes)-1])
+			b.refresh()
+		}
+	case "up", "ctrl-p":
+		b.move(-1)
+	case "down", "ctrl-n":
+		b.move(1)
+	case "pgup":
+		b.move(-page)
+	case "pgdn":
+		b.move(page)
+	case "tab":
+		b.view = (b.view + 1) % view(len(viewNames))
+		b.query = ""
+		b.refresh()
+	case "enter":
+		b.choose()
+	case "esc":
+		b.back()
+	case "ctrl-c", "ctrl-q":
+		b.quit = true
+	case "ctrl-t":
+		if star := b.current(); star != nil {
+			b.ask("Tag with: ", b.addTag)
+		}
+	case "ctrl-r":
+		if star := b.current(); star != nil {
+			b.ask("Remove tag: ", b.removeTag)
+		}
+	case "ctrl-o":
+		if star := b.current(); star != nil {
+			if err := star.OpenInBrowser(false); err != nil {
+				b.status = err.Error()
+			}
+		}
+	}
+}
+
+func (b *Browser) handlePrompt(k key) {
+	switch k.name {
+	case "":
+		b.input += string(k.r)
+	case "backspace":
+		if b.input != "" {
+			runes := []rune(b.input)
+			b.input = string(runes[:len(runes)-1])
+		}
+	case "enter":
+		action, input := b.action, strings.TrimSpace(b.input)
+		b.prompt, b.input, b.action = "", "", nil
+		if input != "" {
+			action(input)
+		}
+	case "esc", "ctrl-c":
+		b.prompt, b.input, b.action = "", "", nil
+	}
+}
+
+func (b *Browser) ask(prompt string, action func(string)) {
+	b.prompt = prompt
+	b.input = ""
+	b.action = action
+}
+
+func (b *Browser) move(delta int) {
+	b.selected += delta
+	if b.selected >= len(b.items) {
+		b.selected = len(b.items) - 1
+	}
+	if b.selected < 0 {
+		b.selected = 0
+	}
+}
+
+func (b *Browser) choose() {
+	if b.selected >= len(b.items) {
+		return
+	}
+	switch b.view {
+	case viewTags:
+		b.tag = b.items[b.selected].name
+	case viewLanguages:
+		b.language = b.items[b.selected].name
+	default:
+		return
+	}
+	b.view = viewStars
+	b.query = ""
+	b.refresh()
+}
+
+func (b *Browser) back() {
+	switch {
+	case b.query != "":
+		b.query = ""
+	case b.tag != "" || b.language != "":
+		b.tag, b.language = "", ""
+	case b.view != viewStars:
+		b.view = viewStars
+	default:
+		b.quit = true
+		return
+	}
+	b.refresh()
+}
+
+func (b *Browser) current() *model.Star {
+	if b.view != viewStars || b.selected >= len(b.items) {
+		return nil
+	}
+	return b.items[b.selected].star
+}
+
+func (b *Browser) addTag(name string) {
+	star := b.current()
+	op := model.NewOperation(fmt.Sprintf("browse: tag %s %s", *star.FullName, name))
+	tag, _, err := op.FindOrCreateTagByName(b.db, name)
+	if err == nil {
+		if star.HasTag(tag) {
+			b.status = fmt.Sprintf("Already tagged '%s'", tag.Name)
+			return
+		}
+		err = op.AddTag(b.db, star, tag)
+	}
+	b.afterTagging(star, err, fmt.Sprintf("Added tag '%s'", name))
+}
+
+func (b *Browser) removeTag(name string) {
+	star := b.current()
+	tag, err := model.FindTagByName(b.db, name)
+	if err == nil {
+		if tag == nil || !star.HasTag(tag) {
+			b.status = fmt.Sprintf("'%s' isn't tagged with '%s'", *star.FullName, name)
+			return
+		}
+		err = model.NewOperation(fmt.Sprintf("browse: untag %s %s", *star.FullName, name)).RemoveTag(b.db, star, tag)
+	}
+	b.afterTagging(star, err, fmt.Sprintf("Removed tag '%s'", name))
+}
+
+func (b *Browser) afterTagging(star *model.Star, err error, message string) {
+	if err == nil {
+		err = star.Index(b.index, b.db)
+	}
+	if err != nil {
+		b.status = err.Error()
+		return
+	}
+	b.status = message
+}
+
+func (b *Browser) refresh() {
+	switch b.view {
+	case viewTags:
+		b.items = b.countItems(func(star *model.Star) []string {
+			names := make([]string, 0, len(star.Tags))
+			for _, tag := range star.Tags {
+				names = append(names, tag.Name)
+			}
+			return names
+		})
+	case viewLanguages:
+		b.items = b.countItems(func(star *model.Star) []string {
+			if star.Language == nil || *star.Language == "" {
+				return nil
+			}
+			return []string{*star.Language}
+		})
+	default:
+		b.items = b.starItems()
+	}
+	b.selected, b.offset = 0, 0
+}
+
+func (b *Browser) starItems() []item {
+	candidates := b.stars
+	// A query of only spaces has nothing to search for, so it matches everything
+	if strings.TrimSpace(b.query) != "" {
+		var err error
+		if candidates, err = b.search(); err != nil {
+			b.status = err.Error()
+		}
+	}
+
+	items := make([]item, 0, len(candidates))
+	for _, star := range candidates {
+		if b.language != "" && (star.Language == nil || !strings.EqualFold(*star.Language, b.language)) {
+			continue
+		}
+		if b.tag != "" && !star.HasTag(&model.Tag{Name: b.tag}) {
+			continue
+		}
+		items = append(items, item{
+			label: *star.FullName,
+			star:  star,
+		})
+	}
+	return items
+}
+
+func (b *Browser) search() ([]*model.Star, error) {
+	request := bleve.NewSearchRequestOptions(model.NewIncrementalQuery(b.query), maxHits, 0, false)
+	results, err := b.index.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	stars := make([]*model.Star, 0, len(results.Hits))
+	for _, hit := range results.Hits {
+		if star, ok := b.byID[hit.ID]; ok {
+			stars = append(stars, star)
+		}
+	}
+	return stars, nil
+}
+
+func (b *Browser) countItems(names func(*model.Star) []string) []item {
+	counts := make(map[string]int)
+	for _, star := range b.stars {
+		for _, name := range names(star) {
+			counts[name]++
+		}
+	}
+
+	items := make([]item, 0, len(counts))
+	for name, count := range counts {
+		if b.query == "" || strings.Contains(strings.ToLower(name), strings.ToLower(b.query)) {
+			items = append(items, item{
+				label: fmt.Sprintf("%s (%d)", name, count),
+				name:  name,
+				count: count,
+			})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return strings.ToLower(items[i].name) < strings.ToLower(items[j].name)
+	})
+	return items
+}
+
+func (b *Browser) render(width, height int) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString(home)
+
+	if width < 20 || height < 5 {
+		buffer.WriteString(clearScreen)
+		buffer.WriteString("Too small")
+		return buffer.Bytes()
+	}
+
+	// Header and search box
+	header := fmt.Sprintf(" limo browse | %s", viewNames[b.view])
+	if b.tag != "" {
+		header += fmt.Sprintf(" | tag: %s", b.tag)
+	}
+	if b.language != "" {
+		header += fmt.Sprintf(" | language: %s", b.language)
+	}
+	writeLine(&buffer, reverse+pad(header, width)+reset)
+	writeLine(&buffer, pad(fmt.Sprintf(" Search: %s", b.query), width))
+
+	// Keep the selected item visible
+	rows := height - 3
+	if b.selected < b.offset {
+		b.offset = b.selected
+	}
+	if b.selected >= b.offset+rows {
+		b.offset = b.selected - rows + 1
+	}
+
+	leftWidth := width * 2 / 5
+	rightWidth := width - leftWidth - 3
+	details := b.details(rightWidth)
+
+	for row := 0; row < rows; row++ {
+		left := ""
+		i := b.offset + row
+		if i < len(b.items) {
+			left = pad(" "+b.items[i].label, leftWidth)
+			if i == b.selected {
+				left = reverse + left + reset
+			}
+		} else {
+			left = pad("", leftWidth)
+		}
+
+		right := ""
+		if row < len(details) {
+			right = details[row]
+		}
+		writeLine(&buffer, left+" │ "+pad(right, rightWidth))
+	}
+
+	// Status line
+	status := help
+	if b.prompt != "" {
+		status = b.prompt + b.input
+	} else if b.status != "" {
+		status = b.status
+	}
+	buffer.WriteString(reverse + pad(" "+status, width) + reset)
+	return buffer.Bytes()
+}
+
+func (b *Browser) details(width int) []string {
+	if b.selected >= len(b.items) {
+		return nil
+	}
+
+	selected := b.items[b.selected]
+	if selected.star == nil {
+		return []string{fmt.Sprintf("%s: %d stars", selected.name, selected.count), "", "Press Enter to list them"}
+	}
+
+	star := selected.star
+	lines := []string{*star.FullName}
+
+	summary := fmt.Sprintf("★ %d", star.Stargazers)
+	if star.Language != nil {
+		summary += " " + *star.Language
+	}
+	lines = append(lines, summary)
+
+	if star.URL != nil {
+		lines = append(lines, *star.URL)
+	}
+
+	if len(star.Tags) > 0 {
+		names := make([]string, 0, len(star.Tags))
+		for _, tag := range star.Tags {
+			names = append(names, tag.Name)
+		}
+		lines = append(lines, wrap("Tags: "+strings.Join(names, ", "), width)...)
+	}
+
+	if star.Description != nil && *star.Description != "" {
+		lines = append(lines, "")
+		lines = append(lines, wrap(*star.Description, width)...)
+		lines = append(lines, "")
+	}
+
+	if star.Homepage != nil && *star.Homepage != "" {
+		lines = append(lines, fmt.Sprintf("Home page: %s", *star.Homepage))
+	}
+
+	lines = append(lines, fmt.Sprintf("Starred on %s", star.StarredAt.Format(time.UnixDate)))
+
+	if star.LocalPath != nil && *star.LocalPath != "" {
+		lines = append(lines, fmt.Sprintf("Local path: %s", *star.LocalPath))
+	}
+	return lines
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hoop33/limo/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBrowser() *Browser {
+	golang := "Go"
+	vim := "VimL"
+	names := []string{"hoop33/limo", "jaxbot/github-issues.vim", "spf13/cobra"}
+	languages := []*string{&golang, &vim, &golang}
+
+	b := &Browser{}
+	for i, name := range names {
+		fullName := name
+		b.stars = append(b.stars, &model.Star{
+			FullName: &fullName,
+			Language: languages[i],
+			Tags:     []model.Tag{{Name: "cli"}},
+		})
+	}
+	b.refresh()
+	return b
+}
+
+func TestBrowserShouldListAllStars(t *testing.T) {
+	b := newTestBrowser()
+	assert.Equal(t, 3, len(b.items))
+	assert.Equal(t, "hoop33/limo", *b.current().FullName)
+}
+
+func TestBrowserShouldMoveWithinBounds(t *testing.T) {
+	b := newTestBrowser()
+	b.handle(key{name: "up"}, 24)
+	assert.Equal(t, 0, b.selected)
+	b.handle(key{name: "pgdn"}, 24)
+	assert.Equal(t, 2, b.selected)
+}
+
+func TestBrowserShouldFilterByLanguage(t *testing.T) {
+	b := newTestBrowser()
+	b.handle(key{name: "tab"}, 24)
+	b.handle(key{name: "tab"}, 24)
+	assert.Equal(t, viewLanguages, b.view)
+	assert.Equal(t, "Go (2)", b.items[0].label)
+
+	b.handle(key{name: "enter"}, 24)
+	assert.Equal(t, viewStars, b.view)
+	assert.Equal(t, 2, len(b.items))
+
+	b.handle(key{name: "esc"}, 24)
+	assert.Equal(t, 3, len(b.items))
+
+	b.handle(key{name: "esc"}, 24)
+	assert.True(t, b.quit)
+}
+
+func TestBrowserShouldListAllStarsForABlankQuery(t *testing.T) {
+	b := newTestBrowser()
+	b.handle(key{r: ' '}, 24)
+	assert.Equal(t, " ", b.query)
+	assert.Equal(t, 3, len(b.items))
+}
+
+func TestBrowserShouldFilterTagsByQuery(t *testing.T) {
+	b := newTestBrowser()
+	b.handle(key{name: "tab"}, 24)
+	b.handle(key{r: 'x'}, 24)
+	assert.Equal(t, 0, len(b.items))
+	b.handle(key{name: "backspace"}, 24)
+	assert.Equal(t, "cli (3)", b.items[0].label)
+}
+
+func TestBrowserShouldCancelPrompt(t *testing.T) {
+	b := newTestBrowser()
+	b.handle(key{name: "ctrl-t"}, 24)
+	assert.Equal(t, "Tag with: ", b.prompt)
+	b.handle(key{r: 'x'}, 24)
+	b.handle(key{name: "esc"}, 24)
+	assert.Equal(t, "", b.prompt)
+	assert.False(t, b.quit)
+}
+
+func TestBrowserShouldRenderDetails(t *testing.T) {
+	b := newTestBrowser()
+	screen := string(b.render(80, 10))
+	assert.True(t, strings.Contains(screen, "hoop33/limo"))
+	assert.True(t, strings.Contains(screen, "Tags: cli"))
+}
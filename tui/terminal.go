@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const enterScreen = "\x1b[?1049h\x1b[?25l"
+const exitScreen = "\x1b[?25h\x1b[?1049l"
+const clearScreen = "\x1b[2J"
+const home = "\x1b[H"
+const reverse = "\x1b[7m"
+const reset = "\x1b[0m"
+
+// key is a key press: a named key, or a rune when name is empty
+type key struct {
+	name string
+	r    rune
+}
+
+var escapeSequences = map[string]string{
+	"[A":  "up",
+	"[B":  "down",
+	"[C":  "right",
+	"[D":  "left",
+	"[H":  "home",
+	"[F":  "end",
+	"[5~": "pgup",
+	"[6~": "pgdn",
+	"OA":  "up",
+	"OB":  "down",
+	"OC":  "right",
+	"OD":  "left",
+}
+
+func parseKeys(data []byte) []key {
+	var keys []key
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == 0x1b:
+			name, size := parseEscape(data[i+1:])
+			keys = append(keys, key{name: name})
+			i += size + 1
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, key{name: "enter"})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, key{name: "backspace"})
+		case c == '\t':
+			keys = append(keys, key{name: "tab"})
+		case c < 0x20:
+			keys = append(keys, key{name: fmt.Sprintf("ctrl-%c", c+'a'-1)})
+		default:
+			r, size := utf8.DecodeRune(data[i:])
+			keys = append(keys, key{r: r})
+			i += size
+			continue
+		}
+		i++
+	}
+	return keys
+}
+
+func parseEscape(data []byte) (string, int) {
+	for sequence, name := range escapeSequences {
+		if bytes.HasPrefix(data, []byte(sequence)) {
+			return name, len(sequence)
+		}
+	}
+	return "esc", 0
+}
+
+func writeLine(buffer *bytes.Buffer, s string) {
+	buffer.WriteString(s)
+	buffer.WriteString("\r\n")
+}
+
+func pad(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		if width < 1 {
+			return ""
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+func wrap(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
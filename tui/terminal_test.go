@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeysShouldParseRunes(t *testing.T) {
+	assert.Equal(t, []key{{r: 'g'}, {r: 'ö'}}, parseKeys([]byte("gö")))
+}
+
+func TestParseKeysShouldParseNamedKeys(t *testing.T) {
+	keys := parseKeys([]byte("\x1b[A\x1b[B\x1b[5~\r\t\x7f\x14"))
+	assert.Equal(t, []key{
+		{name: "up"},
+		{name: "down"},
+		{name: "pgup"},
+		{name: "enter"},
+		{name: "tab"},
+		{name: "backspace"},
+		{name: "ctrl-t"},
+	}, keys)
+}
+
+func TestParseKeysShouldParseLoneEscape(t *testing.T) {
+	assert.Equal(t, []key{{name: "esc"}}, parseKeys([]byte("\x1b")))
+}
+
+func TestPadShouldPadAndTruncate(t *testing.T) {
+	assert.Equal(t, "abc  ", pad("abc", 5))
+	assert.Equal(t, "ab…", pad("abcdef", 3))
+}
+
+func TestWrapShouldWrapOnWords(t *testing.T) {
+	assert.Equal(t, []string{"a CLI for", "managing", "stars"}, wrap("a CLI for managing stars", 10))
+}